@@ -0,0 +1,37 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DataDir                  = "data"
+	IdempotencyFile          = "idempotency.json"
+	DefaultIdempotencyWindow = 24 * time.Hour
+)
+
+// GetDataDir 返回本地数据目录（程序运行目录下的 data/）
+func GetDataDir() string {
+	wd, err := os.Getwd()
+	if err != nil || wd == "" {
+		return filepath.Join(os.TempDir(), "xiaohongshu-mcp", DataDir)
+	}
+	return filepath.Join(wd, DataDir)
+}
+
+// GetIdempotencyStorePath 返回幂等记录文件路径
+func GetIdempotencyStorePath() string {
+	return filepath.Join(GetDataDir(), IdempotencyFile)
+}
+
+// GetIdempotencyWindow 返回幂等键的有效期，可通过环境变量 MCP_IDEMPOTENCY_WINDOW 配置（如 "2h"、"30m"）。
+func GetIdempotencyWindow() time.Duration {
+	if v := os.Getenv("MCP_IDEMPOTENCY_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultIdempotencyWindow
+}
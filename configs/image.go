@@ -3,12 +3,50 @@ package configs
 import (
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	ImagesDir = "xiaohongshu_images"
+
+	DefaultImageCacheMaxMB  = 500
+	DefaultImageCacheMaxAge = 7 * 24 * time.Hour
 )
 
 func GetImagesPath() string {
 	return filepath.Join(os.TempDir(), ImagesDir)
 }
+
+// GetImageCacheMaxBytes 返回图片缓存容量，可通过环境变量 MCP_IMAGE_CACHE_MAX_MB 配置（单位 MB）
+func GetImageCacheMaxBytes() int64 {
+	if v := os.Getenv("MCP_IMAGE_CACHE_MAX_MB"); v != "" {
+		if mb, err := strconv.ParseInt(v, 10, 64); err == nil && mb > 0 {
+			return mb << 20
+		}
+	}
+	return DefaultImageCacheMaxMB << 20
+}
+
+// GetImageCacheMaxAge 返回缓存图片的最长未使用时间，可通过环境变量 MCP_IMAGE_CACHE_MAX_AGE 配置（如 "72h"）
+func GetImageCacheMaxAge() time.Duration {
+	if v := os.Getenv("MCP_IMAGE_CACHE_MAX_AGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultImageCacheMaxAge
+}
+
+// GetImageHostAllowlist 返回允许下载图片的域名白名单，通过环境变量 MCP_IMAGE_HOST_ALLOWLIST 配置（逗号分隔，
+// 如 "xhscdn.com,example.com"，同时匹配子域名），为空时不限制域名
+func GetImageHostAllowlist() []string {
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv("MCP_IMAGE_HOST_ALLOWLIST"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
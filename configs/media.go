@@ -0,0 +1,31 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	MediaDir = "media"
+
+	DefaultMediaMaxMB = 1024
+)
+
+// GetMediaPath 返回笔记媒体归档目录，可通过环境变量 MCP_MEDIA_DIR 配置，默认为 data/media
+func GetMediaPath() string {
+	if v := os.Getenv("MCP_MEDIA_DIR"); v != "" {
+		return v
+	}
+	return filepath.Join(GetDataDir(), MediaDir)
+}
+
+// GetMediaMaxBytes 返回单个媒体文件的最大字节数，可通过环境变量 MCP_MEDIA_MAX_MB 配置（单位 MB）
+func GetMediaMaxBytes() int64 {
+	if v := os.Getenv("MCP_MEDIA_MAX_MB"); v != "" {
+		if mb, err := strconv.ParseInt(v, 10, 64); err == nil && mb > 0 {
+			return mb << 20
+		}
+	}
+	return DefaultMediaMaxMB << 20
+}
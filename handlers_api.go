@@ -3,15 +3,20 @@ package main
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "net/http"
     "os"
+    "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
     "github.com/go-rod/rod"
     "github.com/sirupsen/logrus"
     "github.com/xpzouying/xiaohongshu-mcp/browser"
     "github.com/xpzouying/xiaohongshu-mcp/cookies"
+    "github.com/xpzouying/xiaohongshu-mcp/pkg/idempotency"
+    "github.com/xpzouying/xiaohongshu-mcp/pkg/validator"
     "github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
@@ -90,6 +95,30 @@ func (s *AppServer) publishHandler(c *gin.Context) {
         return
     }
 
+    if _, err := xiaohongshu.ParseVisibility(req.Visibility); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_VISIBILITY",
+            "可见范围参数错误", err.Error())
+        return
+    }
+
+    if _, err := xiaohongshu.ParseProductMatchMode(req.ProductMatch); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_MATCH",
+            "商品匹配方式参数错误", err.Error())
+        return
+    }
+
+    if _, err := xiaohongshu.ParseProductFailurePolicy(req.ProductPolicy); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_PRODUCT_POLICY",
+            "商品失败处理策略参数错误", err.Error())
+        return
+    }
+
+    if _, err := req.ImageOptions.parse(); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_IMAGE_OPTIONS",
+            "图片处理参数错误", err.Error())
+        return
+    }
+
     logrus.Infof("收到发布请求: 标题=%s, 内容长度=%d, 图片数量=%d, 标签数量=%d, 商品数量=%d",
         req.Title, len(req.Content), len(req.Images), len(req.Tags), len(req.Products))
 
@@ -97,13 +126,28 @@ func (s *AppServer) publishHandler(c *gin.Context) {
     result, err := s.xiaohongshuService.PublishContent(c.Request.Context(), &req)
     if err != nil {
         logrus.Errorf("发布内容失败: %v", err)
+        var verr *validator.ValidationError
+        if errors.As(err, &verr) {
+            respondError(c, http.StatusUnprocessableEntity, "VALIDATION_FAILED",
+                "内容校验失败", verr.Violations)
+            return
+        }
+        if errors.Is(err, idempotency.ErrInProgress) || errors.Is(err, idempotency.ErrKeyReused) {
+            respondError(c, http.StatusConflict, "IDEMPOTENCY_CONFLICT",
+                "幂等键冲突", err.Error())
+            return
+        }
         respondError(c, http.StatusInternalServerError, "PUBLISH_FAILED",
             "发布失败", err.Error())
         return
     }
 
-    logrus.Infof("发布成功: %+v", result)
-    respondSuccess(c, result, "发布成功")
+    logrus.Infof("发布成功: 标题=%s, 状态=%s", result.Title, result.Status)
+    message := "发布成功"
+    if result.DryRun {
+        message = "试运行成功"
+    }
+    respondSuccess(c, result, message)
 }
 
 // aiGenerateHandler AI生成并可选发布
@@ -160,12 +204,40 @@ func (s *AppServer) aiGenerateHandler(c *gin.Context) {
     respondSuccess(c, result, "AI生成成功")
 }
 
+// listProductsHandler 获取商品列表
+func (s *AppServer) listProductsHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    result, err := s.xiaohongshuService.ListProducts(c.Request.Context())
+    if err != nil {
+        respondError(c, http.StatusInternalServerError, "LIST_PRODUCTS_FAILED",
+            "获取商品列表失败", err.Error())
+        return
+    }
+
+    respondSuccess(c, result, "获取商品列表成功")
+}
+
 // listFeedsHandler 获取Feeds列表
 func (s *AppServer) listFeedsHandler(c *gin.Context) {
     setSessionFromRequest(c)
     // 获取 Feeds 列表
-    result, err := s.xiaohongshuService.ListFeeds(c.Request.Context())
+    limit, ok := nonNegativeIntQuery(c, "limit")
+    if !ok {
+        respondError(c, http.StatusBadRequest, "INVALID_LIMIT",
+            "limit参数错误", "limit must be a non-negative integer")
+        return
+    }
+
+    result, err := s.xiaohongshuService.ListFeeds(c.Request.Context(), &ListFeedsRequest{
+        Channel: c.Query("channel"),
+        Limit:   limit,
+    })
     if err != nil {
+        if errors.Is(err, xiaohongshu.ErrChannelNotFound) {
+            respondError(c, http.StatusNotFound, "CHANNEL_NOT_FOUND",
+                "未找到频道", err.Error())
+            return
+        }
         respondError(c, http.StatusInternalServerError, "LIST_FEEDS_FAILED",
             "获取Feeds列表失败", err.Error())
         return
@@ -175,6 +247,19 @@ func (s *AppServer) listFeedsHandler(c *gin.Context) {
     respondSuccess(c, result, "获取Feeds列表成功")
 }
 
+// listChannelsHandler 获取首页频道列表
+func (s *AppServer) listChannelsHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    result, err := s.xiaohongshuService.ListChannels(c.Request.Context())
+    if err != nil {
+        respondError(c, http.StatusInternalServerError, "LIST_CHANNELS_FAILED",
+            "获取频道列表失败", err.Error())
+        return
+    }
+
+    respondSuccess(c, result, "获取频道列表成功")
+}
+
 // searchFeedsHandler 搜索Feeds
 func (s *AppServer) searchFeedsHandler(c *gin.Context) {
     setSessionFromRequest(c)
@@ -185,9 +270,36 @@ func (s *AppServer) searchFeedsHandler(c *gin.Context) {
         return
     }
 
+    limit, ok := nonNegativeIntQuery(c, "limit")
+    if !ok {
+        respondError(c, http.StatusBadRequest, "INVALID_LIMIT",
+            "limit参数错误", "limit must be a non-negative integer")
+        return
+    }
+
+    req := &SearchFeedsRequest{
+        Keyword:     keyword,
+        Limit:       limit,
+        Cursor:      c.Query("cursor"),
+        Sort:        c.Query("sort"),
+        NoteType:    c.Query("note_type"),
+        PublishTime: c.Query("publish_time"),
+    }
+
+    if _, err := xiaohongshu.ParseSearchFilters(req.Sort, req.NoteType, req.PublishTime); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_SEARCH_FILTER",
+            "搜索筛选参数错误", err.Error())
+        return
+    }
+
     // 搜索 Feeds
-    result, err := s.xiaohongshuService.SearchFeeds(c.Request.Context(), keyword)
+    result, err := s.xiaohongshuService.SearchFeeds(c.Request.Context(), req)
     if err != nil {
+        if errors.Is(err, xiaohongshu.ErrInvalidCursor) {
+            respondError(c, http.StatusBadRequest, "INVALID_CURSOR",
+                "分页游标错误", err.Error())
+            return
+        }
         respondError(c, http.StatusInternalServerError, "SEARCH_FEEDS_FAILED",
             "搜索Feeds失败", err.Error())
         return
@@ -197,6 +309,333 @@ func (s *AppServer) searchFeedsHandler(c *gin.Context) {
     respondSuccess(c, result, "搜索Feeds成功")
 }
 
+// getFeedDetailHandler 获取笔记详情
+func (s *AppServer) getFeedDetailHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    feedID := c.Query("feed_id")
+    xsecToken := c.Query("xsec_token")
+    if feedID == "" || xsecToken == "" {
+        respondError(c, http.StatusBadRequest, "MISSING_FEED_ID",
+            "缺少笔记ID或xsec_token参数", "feed_id and xsec_token parameters are required")
+        return
+    }
+
+    result, err := s.xiaohongshuService.GetFeedDetail(c.Request.Context(), feedID, xsecToken)
+    if err != nil {
+        if errors.Is(err, xiaohongshu.ErrFeedNotFound) {
+            respondError(c, http.StatusNotFound, "FEED_NOT_FOUND",
+                "笔记不存在或无法访问", err.Error())
+            return
+        }
+        respondError(c, http.StatusInternalServerError, "GET_FEED_DETAIL_FAILED",
+            "获取笔记详情失败", err.Error())
+        return
+    }
+
+    respondSuccess(c, result, "获取笔记详情成功")
+}
+
+// postCommentHandler 发表评论
+func (s *AppServer) postCommentHandler(c *gin.Context) {
+    s.commentHandler(c, false)
+}
+
+// replyCommentHandler 回复评论
+func (s *AppServer) replyCommentHandler(c *gin.Context) {
+    s.commentHandler(c, true)
+}
+
+func (s *AppServer) commentHandler(c *gin.Context, reply bool) {
+    setSessionFromRequest(c)
+
+    var req CommentRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+            "请求参数错误", err.Error())
+        return
+    }
+
+    if reply && req.CommentID == "" {
+        respondError(c, http.StatusBadRequest, "MISSING_COMMENT_ID",
+            "缺少评论ID参数", "comment_id is required")
+        return
+    }
+    if !reply {
+        req.CommentID = ""
+    }
+
+    result, err := s.xiaohongshuService.PostComment(c.Request.Context(), &req)
+    if err != nil {
+        respondInteractionError(c, "POST_COMMENT_FAILED", "评论失败", err)
+        return
+    }
+
+    respondSuccess(c, result, "评论成功")
+}
+
+// likeFeedHandler 点赞或取消点赞笔记
+func (s *AppServer) likeFeedHandler(c *gin.Context) {
+    s.feedInteractHandler(c, "点赞", s.xiaohongshuService.LikeFeed)
+}
+
+// collectFeedHandler 收藏或取消收藏笔记
+func (s *AppServer) collectFeedHandler(c *gin.Context) {
+    s.feedInteractHandler(c, "收藏", s.xiaohongshuService.CollectFeed)
+}
+
+func (s *AppServer) feedInteractHandler(c *gin.Context, name string,
+    interact func(context.Context, *FeedInteractRequest) (*xiaohongshu.InteractResult, error)) {
+    setSessionFromRequest(c)
+
+    var req FeedInteractRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+            "请求参数错误", err.Error())
+        return
+    }
+
+    result, err := interact(c.Request.Context(), &req)
+    if err != nil {
+        respondInteractionError(c, "INTERACT_FAILED", name+"失败", err)
+        return
+    }
+
+    respondSuccess(c, result, name+"操作成功")
+}
+
+// followUserHandler 关注或取消关注用户
+func (s *AppServer) followUserHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+
+    var req FollowRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+            "请求参数错误", err.Error())
+        return
+    }
+
+    result, err := s.xiaohongshuService.FollowUser(c.Request.Context(), &req)
+    if err != nil {
+        respondInteractionError(c, "FOLLOW_FAILED", "关注失败", err)
+        return
+    }
+
+    respondSuccess(c, result, "关注操作成功")
+}
+
+// userProfileHandler 获取用户主页信息
+func (s *AppServer) userProfileHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    userID := c.Query("user_id")
+    if userID == "" {
+        respondError(c, http.StatusBadRequest, "MISSING_USER_ID",
+            "缺少用户ID参数", "user_id parameter is required")
+        return
+    }
+
+    limit, ok := nonNegativeIntQuery(c, "limit")
+    if !ok {
+        respondError(c, http.StatusBadRequest, "INVALID_LIMIT",
+            "limit参数错误", "limit must be a non-negative integer")
+        return
+    }
+
+    result, err := s.xiaohongshuService.GetUserProfile(c.Request.Context(), userID, c.Query("xsec_token"), limit)
+    if err != nil {
+        if errors.Is(err, xiaohongshu.ErrUserNotFound) {
+            respondError(c, http.StatusNotFound, "USER_NOT_FOUND",
+                "用户不存在或主页无法访问", err.Error())
+            return
+        }
+        respondError(c, http.StatusInternalServerError, "GET_USER_PROFILE_FAILED",
+            "获取用户主页失败", err.Error())
+        return
+    }
+
+    respondSuccess(c, result, "获取用户主页成功")
+}
+
+// downloadFeedMediaHandler 下载笔记的图片和视频
+func (s *AppServer) downloadFeedMediaHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+
+    var req DownloadMediaRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+            "请求参数错误", err.Error())
+        return
+    }
+
+    result, err := s.xiaohongshuService.DownloadFeedMedia(c.Request.Context(), &req)
+    if err != nil {
+        switch {
+        case errors.Is(err, xiaohongshu.ErrFeedNotFound):
+            respondError(c, http.StatusNotFound, "FEED_NOT_FOUND",
+                "笔记不存在或无法访问", err.Error())
+        case errors.Is(err, xiaohongshu.ErrNoMedia):
+            respondError(c, http.StatusUnprocessableEntity, "NO_MEDIA",
+                "笔记中没有可下载的图片或视频", err.Error())
+        default:
+            respondError(c, http.StatusInternalServerError, "DOWNLOAD_MEDIA_FAILED",
+                "下载笔记媒体失败", err.Error())
+        }
+        return
+    }
+
+    respondSuccess(c, result, "下载笔记媒体成功")
+}
+
+// imageCacheStatsHandler 查看图片缓存
+func (s *AppServer) imageCacheStatsHandler(c *gin.Context) {
+    respondSuccess(c, s.xiaohongshuService.GetImageCacheStats(), "获取图片缓存成功")
+}
+
+// purgeImageCacheHandler 清理图片缓存
+func (s *AppServer) purgeImageCacheHandler(c *gin.Context) {
+    var olderThan time.Duration
+    if v := c.Query("older_than"); v != "" {
+        d, err := time.ParseDuration(v)
+        if err != nil || d <= 0 {
+            respondError(c, http.StatusBadRequest, "INVALID_OLDER_THAN",
+                "older_than参数错误", "older_than must be a positive duration such as 24h")
+            return
+        }
+        olderThan = d
+    }
+
+    result, err := s.xiaohongshuService.PurgeImageCache(olderThan)
+    if err != nil {
+        respondError(c, http.StatusInternalServerError, "PURGE_CACHE_FAILED",
+            "清理图片缓存失败", err.Error())
+        return
+    }
+
+    respondSuccess(c, result, "清理图片缓存成功")
+}
+
+// getNoteStatusHandler 查询笔记审核状态
+func (s *AppServer) getNoteStatusHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    noteID := c.Query("note_id")
+    title := c.Query("title")
+    if noteID == "" && title == "" {
+        respondError(c, http.StatusBadRequest, "MISSING_NOTE_ID",
+            "缺少笔记ID参数", "note_id or title parameter is required")
+        return
+    }
+
+    result, err := s.xiaohongshuService.GetNoteStatus(c.Request.Context(), noteID, title)
+    if err != nil {
+        if errors.Is(err, xiaohongshu.ErrNoteNotFound) {
+            respondError(c, http.StatusNotFound, "NOTE_NOT_FOUND",
+                "未找到笔记", err.Error())
+            return
+        }
+        respondError(c, http.StatusInternalServerError, "GET_NOTE_STATUS_FAILED",
+            "获取笔记状态失败", err.Error())
+        return
+    }
+
+    respondSuccess(c, result, "获取笔记状态成功")
+}
+
+// editNoteHandler 修改笔记
+func (s *AppServer) editNoteHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    noteID := c.Param("note_id")
+
+    var req EditNoteRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        respondError(c, http.StatusBadRequest, "INVALID_REQUEST",
+            "请求参数错误", err.Error())
+        return
+    }
+
+    if !req.Confirm {
+        respondError(c, http.StatusBadRequest, "CONFIRMATION_REQUIRED",
+            "修改笔记需要确认", "set confirm to true to edit the note")
+        return
+    }
+
+    if err := s.xiaohongshuService.EditNote(c.Request.Context(), noteID, &req); err != nil {
+        respondNoteError(c, "EDIT_NOTE_FAILED", "修改笔记失败", err)
+        return
+    }
+
+    respondSuccess(c, map[string]string{"note_id": noteID}, "修改笔记成功")
+}
+
+// deleteNoteHandler 删除笔记
+func (s *AppServer) deleteNoteHandler(c *gin.Context) {
+    setSessionFromRequest(c)
+    noteID := c.Param("note_id")
+
+    if confirm, _ := parseBool(c.Query("confirm")); !confirm {
+        respondError(c, http.StatusBadRequest, "CONFIRMATION_REQUIRED",
+            "删除笔记需要确认", "set confirm=true to delete the note")
+        return
+    }
+
+    if err := s.xiaohongshuService.DeleteNote(c.Request.Context(), noteID); err != nil {
+        respondNoteError(c, "DELETE_NOTE_FAILED", "删除笔记失败", err)
+        return
+    }
+
+    respondSuccess(c, map[string]string{"note_id": noteID}, "删除笔记成功")
+}
+
+// respondNoteError 按错误类型返回笔记操作的错误响应
+func respondNoteError(c *gin.Context, code, message string, err error) {
+    var verr *validator.ValidationError
+    switch {
+    case errors.As(err, &verr):
+        respondError(c, http.StatusUnprocessableEntity, "VALIDATION_FAILED",
+            "内容校验失败", verr.Violations)
+    case errors.Is(err, xiaohongshu.ErrNoteNotFound):
+        respondError(c, http.StatusNotFound, "NOTE_NOT_FOUND",
+            "未找到笔记", err.Error())
+    default:
+        respondError(c, http.StatusInternalServerError, code, message, err.Error())
+    }
+}
+
+// respondInteractionError 将评论、点赞等互动操作的错误映射为对应的 HTTP 状态码
+func respondInteractionError(c *gin.Context, code, message string, err error) {
+    switch {
+    case errors.Is(err, xiaohongshu.ErrNotLoggedIn):
+        respondError(c, http.StatusUnauthorized, "NOT_LOGGED_IN",
+            "未登录", err.Error())
+    case errors.Is(err, xiaohongshu.ErrCommentDisabled):
+        respondError(c, http.StatusForbidden, "COMMENT_DISABLED",
+            "笔记已关闭评论", err.Error())
+    case errors.Is(err, xiaohongshu.ErrRateLimited):
+        respondError(c, http.StatusTooManyRequests, "RATE_LIMITED",
+            "操作过于频繁", err.Error())
+    case errors.Is(err, xiaohongshu.ErrFeedNotFound):
+        respondError(c, http.StatusNotFound, "FEED_NOT_FOUND",
+            "笔记不存在或无法访问", err.Error())
+    case errors.Is(err, xiaohongshu.ErrCommentNotFound):
+        respondError(c, http.StatusNotFound, "COMMENT_NOT_FOUND",
+            "未找到评论", err.Error())
+    default:
+        respondError(c, http.StatusInternalServerError, code, message, err.Error())
+    }
+}
+
+// nonNegativeIntQuery 读取非负整数查询参数，缺省时返回 0，格式错误时 ok 为 false
+func nonNegativeIntQuery(c *gin.Context, key string) (value int, ok bool) {
+    v := c.Query(key)
+    if v == "" {
+        return 0, true
+    }
+
+    n, err := strconv.Atoi(v)
+    if err != nil || n < 0 {
+        return 0, false
+    }
+    return n, true
+}
+
 // healthHandler 健康检查
 func healthHandler(c *gin.Context) {
     respondSuccess(c, map[string]any{
@@ -3,9 +3,13 @@ package main
 import (
     "context"
     "encoding/json"
+    "errors"
     "fmt"
+    "strings"
 
     "github.com/sirupsen/logrus"
+    "github.com/xpzouying/xiaohongshu-mcp/pkg/validator"
+    "github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // MCP 工具处理函数
@@ -44,6 +48,14 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]in
     imagePathsInterface, _ := args["images"].([]interface{})
     tagsInterface, _ := args["tags"].([]interface{})
     productsInterface, _ := args["products"].([]interface{})
+    mentionsInterface, _ := args["mentions"].([]interface{})
+    visibility, _ := args["visibility"].(string)
+    location, _ := args["location"].(string)
+    isOriginal, _ := args["is_original"].(bool)
+    dryRun, _ := args["dry_run"].(bool)
+    idempotencyKey, _ := args["idempotency_key"].(string)
+
+    coverIndex := intArg(args, "cover_index")
 
     var imagePaths []string
     for _, path := range imagePathsInterface {
@@ -66,6 +78,32 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]in
         }
     }
 
+    var mentions []string
+    for _, mention := range mentionsInterface {
+        if mentionStr, ok := mention.(string); ok {
+            mentions = append(mentions, mentionStr)
+        }
+    }
+
+    if _, err := xiaohongshu.ParseVisibility(visibility); err != nil {
+        return errorToolResult("发布失败: " + err.Error())
+    }
+
+    productMatch, _ := args["product_match"].(string)
+    if _, err := xiaohongshu.ParseProductMatchMode(productMatch); err != nil {
+        return errorToolResult("发布失败: " + err.Error())
+    }
+
+    productPolicy, _ := args["product_policy"].(string)
+    if _, err := xiaohongshu.ParseProductFailurePolicy(productPolicy); err != nil {
+        return errorToolResult("发布失败: " + err.Error())
+    }
+
+    imageOptions := imageOptionsArg(args, "image_options")
+    if _, err := imageOptions.parse(); err != nil {
+        return errorToolResult("发布失败: " + err.Error())
+    }
+
     logrus.Infof("MCP: 发布内容 - 标题: %s, 图片数量: %d, 标签数量: %d, 商品数量: %d",
         title, len(imagePaths), len(tags), len(products))
 
@@ -76,11 +114,36 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]in
         Images:   imagePaths,
         Tags:     tags,
         Products: products,
+
+        ProductIDs:    stringArrayArg(args, "product_ids"),
+        ProductMatch:  productMatch,
+        ProductPolicy: productPolicy,
+
+        CoverIndex: coverIndex,
+        Visibility: visibility,
+        Location:   location,
+        Mentions:   mentions,
+        IsOriginal: isOriginal,
+
+        DryRun:         dryRun,
+        IdempotencyKey: idempotencyKey,
+        ImageOptions:   imageOptions,
     }
 
     // 执行发布
     result, err := s.xiaohongshuService.PublishContent(ctx, req)
     if err != nil {
+        var verr *validator.ValidationError
+        if errors.As(err, &verr) {
+            jsonData, _ := json.MarshalIndent(verr, "", "  ")
+            return &MCPToolResult{
+                Content: []MCPContent{{
+                    Type: "text",
+                    Text: "发布失败，内容校验未通过:\n" + string(jsonData),
+                }},
+                IsError: true,
+            }
+        }
         return &MCPToolResult{
             Content: []MCPContent{{
                 Type: "text",
@@ -90,6 +153,10 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]in
         }
     }
 
+    if result.DryRun {
+        return dryRunToolResult(result)
+    }
+
     resultText := fmt.Sprintf("内容发布成功: %+v", result)
     return &MCPToolResult{
         Content: []MCPContent{{
@@ -99,11 +166,227 @@ func (s *AppServer) handlePublishContent(ctx context.Context, args map[string]in
     }
 }
 
+// dryRunToolResult 将试运行结果转换为文本加截图
+func dryRunToolResult(result *PublishResponse) *MCPToolResult {
+    screenshot := result.Screenshot
+
+    summary := *result
+    summary.Screenshot = ""
+    jsonData, err := json.MarshalIndent(summary, "", "  ")
+    if err != nil {
+        return &MCPToolResult{
+            Content: []MCPContent{{
+                Type: "text",
+                Text: fmt.Sprintf("试运行成功，但序列化失败: %v", err),
+            }},
+            IsError: true,
+        }
+    }
+
+    contents := []MCPContent{{
+        Type: "text",
+        Text: "试运行成功，未提交发布:\n" + string(jsonData),
+    }}
+    if screenshot != "" {
+        contents = append(contents, MCPContent{
+            Type:     "image",
+            Data:     screenshot,
+            MimeType: "image/png",
+        })
+    }
+
+    return &MCPToolResult{Content: contents}
+}
+
+// handleGetNoteStatus 处理查询笔记审核状态
+func (s *AppServer) handleGetNoteStatus(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 查询笔记状态")
+
+    noteID, _ := args["note_id"].(string)
+    title, _ := args["title"].(string)
+    if noteID == "" && title == "" {
+        return &MCPToolResult{
+            Content: []MCPContent{{
+                Type: "text",
+                Text: "查询笔记状态失败: 缺少笔记ID或标题参数",
+            }},
+            IsError: true,
+        }
+    }
+
+    result, err := s.xiaohongshuService.GetNoteStatus(ctx, noteID, title)
+    if err != nil {
+        return &MCPToolResult{
+            Content: []MCPContent{{
+                Type: "text",
+                Text: "查询笔记状态失败: " + err.Error(),
+            }},
+            IsError: true,
+        }
+    }
+
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return &MCPToolResult{
+            Content: []MCPContent{{
+                Type: "text",
+                Text: fmt.Sprintf("查询笔记状态成功，但序列化失败: %v", err),
+            }},
+            IsError: true,
+        }
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
+
+// handleEditNote 处理修改笔记
+func (s *AppServer) handleEditNote(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 修改笔记")
+
+    noteID, _ := args["note_id"].(string)
+    if noteID == "" {
+        return errorToolResult("修改笔记失败: 缺少笔记ID参数")
+    }
+
+    if confirm, _ := args["confirm"].(bool); !confirm {
+        return errorToolResult("修改笔记失败: 需要将 confirm 设置为 true 以确认修改")
+    }
+
+    title, _ := args["title"].(string)
+    content, _ := args["content"].(string)
+
+    req := &EditNoteRequest{
+        Title:   title,
+        Content: content,
+        Tags:    stringArrayArg(args, "tags"),
+        Images:  stringArrayArg(args, "images"),
+        Confirm: true,
+    }
+
+    if err := s.xiaohongshuService.EditNote(ctx, noteID, req); err != nil {
+        return errorToolResult("修改笔记失败: " + err.Error())
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: "笔记修改成功: " + noteID,
+        }},
+    }
+}
+
+// handleDeleteNote 处理删除笔记
+func (s *AppServer) handleDeleteNote(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 删除笔记")
+
+    noteID, _ := args["note_id"].(string)
+    if noteID == "" {
+        return errorToolResult("删除笔记失败: 缺少笔记ID参数")
+    }
+
+    if confirm, _ := args["confirm"].(bool); !confirm {
+        return errorToolResult("删除笔记失败: 需要将 confirm 设置为 true 以确认删除")
+    }
+
+    if err := s.xiaohongshuService.DeleteNote(ctx, noteID); err != nil {
+        return errorToolResult("删除笔记失败: " + err.Error())
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: "笔记删除成功: " + noteID,
+        }},
+    }
+}
+
+// errorToolResult 构造错误结果
+func errorToolResult(text string) *MCPToolResult {
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: text,
+        }},
+        IsError: true,
+    }
+}
+
+// intArg 读取整数参数，JSON 数字解码为 float64，缺省或类型不符时返回 0
+func intArg(args map[string]interface{}, key string) int {
+    if v, ok := args[key].(float64); ok {
+        return int(v)
+    }
+    return 0
+}
+
+// stringArrayArg 读取字符串数组参数，忽略非字符串元素
+func stringArrayArg(args map[string]interface{}, key string) []string {
+    items, _ := args[key].([]interface{})
+
+    var result []string
+    for _, item := range items {
+        if str, ok := item.(string); ok {
+            result = append(result, str)
+        }
+    }
+    return result
+}
+
+// imageOptionsArg 读取图片处理参数对象，缺省时返回 nil
+func imageOptionsArg(args map[string]interface{}, key string) *ImageOptions {
+    obj, ok := args[key].(map[string]interface{})
+    if !ok {
+        return nil
+    }
+
+    disabled, _ := obj["disabled"].(bool)
+    aspect, _ := obj["aspect"].(string)
+    fit, _ := obj["fit"].(string)
+
+    return &ImageOptions{
+        Disabled:     disabled,
+        MaxDimension: intArg(obj, "max_dimension"),
+        Aspect:       aspect,
+        Fit:          fit,
+        Quality:      intArg(obj, "quality"),
+    }
+}
+
+// handleListProducts 处理获取商品列表
+func (s *AppServer) handleListProducts(ctx context.Context) *MCPToolResult {
+    logrus.Info("MCP: 获取商品列表")
+
+    result, err := s.xiaohongshuService.ListProducts(ctx)
+    if err != nil {
+        return errorToolResult("获取商品列表失败: " + err.Error())
+    }
+
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return errorToolResult(fmt.Sprintf("获取商品列表成功，但序列化失败: %v", err))
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
+
 // handleListFeeds 处理获取Feeds列表
-func (s *AppServer) handleListFeeds(ctx context.Context) *MCPToolResult {
+func (s *AppServer) handleListFeeds(ctx context.Context, args map[string]interface{}) *MCPToolResult {
     logrus.Info("MCP: 获取Feeds列表")
 
-    result, err := s.xiaohongshuService.ListFeeds(ctx)
+    req := &ListFeedsRequest{Limit: intArg(args, "limit")}
+    req.Channel, _ = args["channel"].(string)
+
+    result, err := s.xiaohongshuService.ListFeeds(ctx, req)
     if err != nil {
         return &MCPToolResult{
             Content: []MCPContent{{
@@ -134,6 +417,28 @@ func (s *AppServer) handleListFeeds(ctx context.Context) *MCPToolResult {
     }
 }
 
+// handleListChannels 处理获取首页频道列表
+func (s *AppServer) handleListChannels(ctx context.Context) *MCPToolResult {
+    logrus.Info("MCP: 获取频道列表")
+
+    result, err := s.xiaohongshuService.ListChannels(ctx)
+    if err != nil {
+        return errorToolResult("获取频道列表失败: " + err.Error())
+    }
+
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return errorToolResult(fmt.Sprintf("获取频道列表成功，但序列化失败: %v", err))
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
+
 // handleSearchFeeds 处理搜索Feeds
 func (s *AppServer) handleSearchFeeds(ctx context.Context, args map[string]interface{}) *MCPToolResult {
     logrus.Info("MCP: 搜索Feeds")
@@ -152,7 +457,20 @@ func (s *AppServer) handleSearchFeeds(ctx context.Context, args map[string]inter
 
     logrus.Infof("MCP: 搜索Feeds - 关键词: %s", keyword)
 
-    result, err := s.xiaohongshuService.SearchFeeds(ctx, keyword)
+    req := &SearchFeedsRequest{
+        Keyword: keyword,
+        Limit:   intArg(args, "limit"),
+    }
+    req.Cursor, _ = args["cursor"].(string)
+    req.Sort, _ = args["sort"].(string)
+    req.NoteType, _ = args["note_type"].(string)
+    req.PublishTime, _ = args["publish_time"].(string)
+
+    if _, err := xiaohongshu.ParseSearchFilters(req.Sort, req.NoteType, req.PublishTime); err != nil {
+        return errorToolResult("搜索Feeds失败: " + err.Error())
+    }
+
+    result, err := s.xiaohongshuService.SearchFeeds(ctx, req)
     if err != nil {
         return &MCPToolResult{
             Content: []MCPContent{{
@@ -183,6 +501,176 @@ func (s *AppServer) handleSearchFeeds(ctx context.Context, args map[string]inter
     }
 }
 
+// handleGetFeedDetail 处理获取笔记详情
+func (s *AppServer) handleGetFeedDetail(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 获取笔记详情")
+
+    feedID, _ := args["feed_id"].(string)
+    xsecToken, _ := args["xsec_token"].(string)
+    if feedID == "" || xsecToken == "" {
+        return errorToolResult("获取笔记详情失败: 缺少笔记ID或xsec_token参数")
+    }
+
+    result, err := s.xiaohongshuService.GetFeedDetail(ctx, feedID, xsecToken)
+    if err != nil {
+        return errorToolResult("获取笔记详情失败: " + err.Error())
+    }
+
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return errorToolResult(fmt.Sprintf("获取笔记详情成功，但序列化失败: %v", err))
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
+
+// handlePostComment 处理发表评论
+func (s *AppServer) handlePostComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 发表评论")
+    return s.comment(ctx, args, false)
+}
+
+// handleReplyComment 处理回复评论
+func (s *AppServer) handleReplyComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 回复评论")
+    return s.comment(ctx, args, true)
+}
+
+func (s *AppServer) comment(ctx context.Context, args map[string]interface{}, reply bool) *MCPToolResult {
+    req := &CommentRequest{}
+    req.FeedID, _ = args["feed_id"].(string)
+    req.XsecToken, _ = args["xsec_token"].(string)
+    req.Content, _ = args["content"].(string)
+    if reply {
+        req.CommentID, _ = args["comment_id"].(string)
+    }
+
+    if req.FeedID == "" || req.XsecToken == "" {
+        return errorToolResult("评论失败: 缺少笔记ID或xsec_token参数")
+    }
+    if strings.TrimSpace(req.Content) == "" {
+        return errorToolResult("评论失败: 评论内容不能为空")
+    }
+    if reply && req.CommentID == "" {
+        return errorToolResult("回复评论失败: 缺少评论ID参数")
+    }
+
+    if _, err := s.xiaohongshuService.PostComment(ctx, req); err != nil {
+        return errorToolResult("评论失败: " + err.Error())
+    }
+
+    text := "评论成功: " + req.FeedID
+    if reply {
+        text = fmt.Sprintf("回复成功: %s 的评论 %s", req.FeedID, req.CommentID)
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: text,
+        }},
+    }
+}
+
+// handleLikeFeed 处理点赞或取消点赞
+func (s *AppServer) handleLikeFeed(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 点赞笔记")
+    return s.feedInteract(ctx, args, "点赞", s.xiaohongshuService.LikeFeed)
+}
+
+// handleCollectFeed 处理收藏或取消收藏
+func (s *AppServer) handleCollectFeed(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 收藏笔记")
+    return s.feedInteract(ctx, args, "收藏", s.xiaohongshuService.CollectFeed)
+}
+
+func (s *AppServer) feedInteract(ctx context.Context, args map[string]interface{}, name string,
+    interact func(context.Context, *FeedInteractRequest) (*xiaohongshu.InteractResult, error)) *MCPToolResult {
+    req := &FeedInteractRequest{}
+    req.FeedID, _ = args["feed_id"].(string)
+    req.XsecToken, _ = args["xsec_token"].(string)
+    req.Undo, _ = args["undo"].(bool)
+
+    if req.FeedID == "" || req.XsecToken == "" {
+        return errorToolResult(name + "失败: 缺少笔记ID或xsec_token参数")
+    }
+
+    result, err := interact(ctx, req)
+    if err != nil {
+        return errorToolResult(name + "失败: " + err.Error())
+    }
+
+    return interactToolResult(result)
+}
+
+// handleFollowUser 处理关注或取消关注用户
+func (s *AppServer) handleFollowUser(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 关注用户")
+
+    req := &FollowRequest{}
+    req.UserID, _ = args["user_id"].(string)
+    req.XsecToken, _ = args["xsec_token"].(string)
+    req.Undo, _ = args["undo"].(bool)
+
+    if req.UserID == "" {
+        return errorToolResult("关注失败: 缺少用户ID参数")
+    }
+
+    result, err := s.xiaohongshuService.FollowUser(ctx, req)
+    if err != nil {
+        return errorToolResult("关注失败: " + err.Error())
+    }
+
+    return interactToolResult(result)
+}
+
+func interactToolResult(result *xiaohongshu.InteractResult) *MCPToolResult {
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return errorToolResult(fmt.Sprintf("操作成功，但序列化失败: %v", err))
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
+
+// handleUserProfile 处理获取用户主页
+func (s *AppServer) handleUserProfile(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 获取用户主页")
+
+    userID, _ := args["user_id"].(string)
+    if userID == "" {
+        return errorToolResult("获取用户主页失败: 缺少用户ID参数")
+    }
+    xsecToken, _ := args["xsec_token"].(string)
+
+    result, err := s.xiaohongshuService.GetUserProfile(ctx, userID, xsecToken, intArg(args, "limit"))
+    if err != nil {
+        return errorToolResult("获取用户主页失败: " + err.Error())
+    }
+
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return errorToolResult(fmt.Sprintf("获取用户主页成功，但序列化失败: %v", err))
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
+
 // handleAIGenerate 处理AI生成内容
 func (s *AppServer) handleAIGenerate(ctx context.Context, args map[string]interface{}) *MCPToolResult {
     logrus.Info("MCP: AI生成内容")
@@ -283,3 +771,32 @@ func (s *AppServer) handleAIGenerate(ctx context.Context, args map[string]interf
         }},
     }
 }
+
+// handleDownloadFeedMedia 处理下载笔记媒体
+func (s *AppServer) handleDownloadFeedMedia(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+    logrus.Info("MCP: 下载笔记媒体")
+
+    req := &DownloadMediaRequest{}
+    req.FeedID, _ = args["feed_id"].(string)
+    req.XsecToken, _ = args["xsec_token"].(string)
+    if req.FeedID == "" || req.XsecToken == "" {
+        return errorToolResult("下载笔记媒体失败: 缺少笔记ID或xsec_token参数")
+    }
+
+    result, err := s.xiaohongshuService.DownloadFeedMedia(ctx, req)
+    if err != nil {
+        return errorToolResult("下载笔记媒体失败: " + err.Error())
+    }
+
+    jsonData, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return errorToolResult(fmt.Sprintf("下载笔记媒体成功，但序列化失败: %v", err))
+    }
+
+    return &MCPToolResult{
+        Content: []MCPContent{{
+            Type: "text",
+            Text: string(jsonData),
+        }},
+    }
+}
@@ -0,0 +1,483 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+const (
+	cacheIndexFile  = "index.json"
+	cacheObjectsDir = "objects"
+	// legacyFilePrefix 旧版本按 URL 和时间戳命名的图片文件前缀
+	legacyFilePrefix = "img_"
+)
+
+// CacheEntry 图片地址对应的缓存记录，多个地址可以指向同一份内容
+type CacheEntry struct {
+	URL          string    `json:"url"`
+	Hash         string    `json:"hash"` // 图片内容的 SHA256
+	Ext          string    `json:"ext"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	LastUsed     time.Time `json:"last_used"`
+}
+
+// CacheStats 缓存使用情况
+type CacheStats struct {
+	Dir        string       `json:"dir"`
+	Entries    int          `json:"entries"`     // 地址数
+	Objects    int          `json:"objects"`     // 去重后的图片文件数
+	TotalBytes int64        `json:"total_bytes"` // 图片文件总字节数
+	MaxBytes   int64        `json:"max_bytes"`
+	MaxAge     string       `json:"max_age"`
+	Items      []CacheEntry `json:"items"`
+}
+
+// ImageCache 按内容哈希存储的图片缓存。
+// 图片保存为 objects/<sha256>.<ext>，index.json 记录地址到哈希的映射以及用于重新验证的 ETag、Last-Modified。
+// 正在被使用的图片文件可以被固定（引用计数），Release 之前清理缓存时不会删除。
+type ImageCache struct {
+	dir string
+
+	mu       sync.Mutex
+	loaded   bool
+	entries  map[string]*CacheEntry
+	pins     map[string]int // 图片文件名 -> 固定次数
+	maxBytes int64
+	maxAge   time.Duration
+
+	now func() time.Time
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[string]*ImageCache)
+)
+
+// OpenImageCache 返回 dir 对应的缓存，同一目录在进程内共享一个实例
+func OpenImageCache(dir string) *ImageCache {
+	dir = filepath.Clean(dir)
+
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	if c, ok := caches[dir]; ok {
+		return c
+	}
+	c := newImageCache(dir)
+	caches[dir] = c
+	return c
+}
+
+func newImageCache(dir string) *ImageCache {
+	return &ImageCache{
+		dir:      dir,
+		entries:  make(map[string]*CacheEntry),
+		pins:     make(map[string]int),
+		maxBytes: configs.DefaultImageCacheMaxMB << 20,
+		maxAge:   configs.DefaultImageCacheMaxAge,
+		now:      time.Now,
+	}
+}
+
+// SetLimits 设置缓存容量和最长未使用时间，<=0 表示不限制
+func (c *ImageCache) SetLimits(maxBytes int64, maxAge time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxBytes = maxBytes
+	c.maxAge = maxAge
+}
+
+// Lookup 查找地址对应的缓存，图片文件已被删除时视为未命中
+func (c *ImageCache) Lookup(imageURL string) (CacheEntry, string, bool) {
+	return c.lookup(imageURL, false)
+}
+
+// lookup 查找地址对应的缓存，pin 为 true 时命中的图片文件被固定，需要调用 Release 释放
+func (c *ImageCache) lookup(imageURL string, pin bool) (CacheEntry, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadLocked()
+
+	entry, ok := c.entries[imageURL]
+	if !ok {
+		return CacheEntry{}, "", false
+	}
+
+	path := c.objectPath(entry.Hash, entry.Ext)
+	if _, err := os.Stat(path); err != nil {
+		delete(c.entries, imageURL)
+		return CacheEntry{}, "", false
+	}
+
+	if pin {
+		c.pins[objectName(entry)]++
+	}
+	return *entry, path, true
+}
+
+// Touch 记录缓存被使用，用于按最近使用时间清理
+func (c *ImageCache) Touch(imageURL string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadLocked()
+
+	entry, ok := c.entries[imageURL]
+	if !ok {
+		return nil
+	}
+	entry.LastUsed = c.now()
+
+	return c.saveLocked()
+}
+
+// Put 保存图片内容并记录地址映射，内容相同的图片只保存一份。返回图片文件路径。
+func (c *ImageCache) Put(imageURL string, data []byte, ext, etag, lastModified string) (string, error) {
+	return c.put(imageURL, data, ext, etag, lastModified, false)
+}
+
+// put 保存图片内容，pin 为 true 时返回的图片文件被固定，需要调用 Release 释放
+func (c *ImageCache) put(imageURL string, data []byte, ext, etag, lastModified string, pin bool) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadLocked()
+
+	path := c.objectPath(hash, ext)
+	if _, err := os.Stat(path); err != nil {
+		if err := writeFileAtomic(path, data); err != nil {
+			return "", errors.Wrap(err, "failed to save image")
+		}
+	}
+
+	now := c.now()
+	entry := &CacheEntry{
+		URL:          imageURL,
+		Hash:         hash,
+		Ext:          ext,
+		Size:         int64(len(data)),
+		ETag:         etag,
+		LastModified: lastModified,
+		FetchedAt:    now,
+		LastUsed:     now,
+	}
+	c.entries[imageURL] = entry
+
+	if pin {
+		c.pins[objectName(entry)]++
+	}
+
+	// 刚保存的图片即将被使用，清理时跳过
+	c.evictLocked(imageURL)
+
+	return path, c.saveLocked()
+}
+
+// Release 释放 lookup/put 固定的图片文件，不属于缓存的路径会被忽略
+func (c *ImageCache) Release(paths ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	objectsDir := filepath.Join(c.dir, cacheObjectsDir)
+	for _, path := range paths {
+		if filepath.Dir(filepath.Clean(path)) != objectsDir {
+			continue
+		}
+
+		name := filepath.Base(path)
+		switch n := c.pins[name]; {
+		case n > 1:
+			c.pins[name] = n - 1
+		case n == 1:
+			delete(c.pins, name)
+		}
+	}
+}
+
+// Evict 按最长未使用时间和容量清理缓存，返回删除的地址数和释放的字节数
+func (c *ImageCache) Evict() (int, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadLocked()
+
+	removed, freed := c.evictLocked("")
+	return removed, freed, c.saveLocked()
+}
+
+// Purge 删除超过 olderThan 未使用的缓存，olderThan<=0 时清空缓存（包括旧版本遗留的图片文件）。
+// 正在被使用（已固定）的图片文件及其地址会保留。返回删除的地址数和释放的字节数。
+func (c *ImageCache) Purge(olderThan time.Duration) (int, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadLocked()
+
+	if olderThan > 0 {
+		cutoff := c.now().Add(-olderThan)
+		removed, freed := c.removeLocked(func(e *CacheEntry) bool { return e.LastUsed.Before(cutoff) })
+		return removed, freed, c.saveLocked()
+	}
+
+	removed, freed := c.removeLocked(func(*CacheEntry) bool { return true })
+	freed += c.removeLegacyFiles()
+
+	orphans, err := c.removeOrphanObjectsLocked()
+	freed += orphans
+	if err != nil {
+		return removed, freed, errors.Wrap(err, "failed to remove cached images")
+	}
+
+	return removed, freed, c.saveLocked()
+}
+
+// Stats 返回缓存使用情况，条目按最近使用时间倒序
+func (c *ImageCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadLocked()
+
+	stats := CacheStats{
+		Dir:        c.dir,
+		Entries:    len(c.entries),
+		Objects:    len(c.objectSizesLocked()),
+		TotalBytes: c.totalBytesLocked(),
+		MaxBytes:   c.maxBytes,
+		MaxAge:     c.maxAge.String(),
+		Items:      make([]CacheEntry, 0, len(c.entries)),
+	}
+	for _, entry := range c.entries {
+		stats.Items = append(stats.Items, *entry)
+	}
+	sort.Slice(stats.Items, func(i, j int) bool {
+		return stats.Items[i].LastUsed.After(stats.Items[j].LastUsed)
+	})
+
+	return stats
+}
+
+// evictLocked 删除超过 maxAge 未使用的条目，再按最近最少使用删除直到不超过 maxBytes。
+// keep 为本次不删除的地址，已固定的图片文件也不会删除。
+func (c *ImageCache) evictLocked(keep string) (int, int64) {
+	var removed int
+	var freed int64
+
+	if c.maxAge > 0 {
+		cutoff := c.now().Add(-c.maxAge)
+		n, f := c.removeLocked(func(e *CacheEntry) bool { return e.URL != keep && e.LastUsed.Before(cutoff) })
+		removed, freed = removed+n, freed+f
+	}
+
+	if c.maxBytes <= 0 || c.totalBytesLocked() <= c.maxBytes {
+		return removed, freed
+	}
+
+	lru := make([]*CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		if entry.URL != keep && c.pins[objectName(entry)] == 0 {
+			lru = append(lru, entry)
+		}
+	}
+	sort.Slice(lru, func(i, j int) bool { return lru[i].LastUsed.Before(lru[j].LastUsed) })
+
+	for _, entry := range lru {
+		if c.totalBytesLocked() <= c.maxBytes {
+			break
+		}
+		n, f := c.removeLocked(func(e *CacheEntry) bool { return e == entry })
+		removed, freed = removed+n, freed+f
+	}
+
+	return removed, freed
+}
+
+// removeLocked 删除满足条件且图片文件未被固定的条目，以及不再被任何条目引用的图片文件
+func (c *ImageCache) removeLocked(match func(*CacheEntry) bool) (int, int64) {
+	before := c.objectSizesLocked()
+
+	removed := 0
+	for url, entry := range c.entries {
+		if c.pins[objectName(entry)] == 0 && match(entry) {
+			delete(c.entries, url)
+			removed++
+		}
+	}
+
+	after := c.objectSizesLocked()
+
+	var freed int64
+	for object, size := range before {
+		if _, ok := after[object]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, cacheObjectsDir, object)); err == nil || os.IsNotExist(err) {
+			freed += size
+		}
+	}
+
+	return removed, freed
+}
+
+// objectSizesLocked 返回被引用的图片文件名及其大小
+func (c *ImageCache) objectSizesLocked() map[string]int64 {
+	objects := make(map[string]int64, len(c.entries))
+	for _, entry := range c.entries {
+		objects[objectName(entry)] = entry.Size
+	}
+	return objects
+}
+
+// removeOrphanObjectsLocked 删除 objects 目录中既没有条目引用也没有被固定的文件，返回释放的字节数
+func (c *ImageCache) removeOrphanObjectsLocked() (int64, error) {
+	files, err := os.ReadDir(filepath.Join(c.dir, cacheObjectsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	referenced := c.objectSizesLocked()
+
+	var freed int64
+	for _, file := range files {
+		if file.IsDir() || c.pins[file.Name()] > 0 {
+			continue
+		}
+		if _, ok := referenced[file.Name()]; ok {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, cacheObjectsDir, file.Name())); err != nil {
+			return freed, err
+		}
+		freed += info.Size()
+	}
+	return freed, nil
+}
+
+func (c *ImageCache) totalBytesLocked() int64 {
+	var total int64
+	for _, size := range c.objectSizesLocked() {
+		total += size
+	}
+	return total
+}
+
+// removeLegacyFiles 删除旧版本遗留的 img_<hash>_<timestamp>.<ext> 文件，返回释放的字节数
+func (c *ImageCache) removeLegacyFiles() int64 {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0
+	}
+
+	var freed int64
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), legacyFilePrefix) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, file.Name())); err == nil {
+			freed += info.Size()
+		}
+	}
+	return freed
+}
+
+// objectName 返回条目对应的图片文件名
+func objectName(entry *CacheEntry) string {
+	return entry.Hash + "." + entry.Ext
+}
+
+func (c *ImageCache) objectPath(hash, ext string) string {
+	return filepath.Join(c.dir, cacheObjectsDir, hash+"."+ext)
+}
+
+// loadLocked 首次使用时从 index.json 读取索引，文件不存在或损坏时从空缓存开始
+func (c *ImageCache) loadLocked() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+
+	data, err := os.ReadFile(filepath.Join(c.dir, cacheIndexFile))
+	if err != nil {
+		return
+	}
+
+	var entries []*CacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.URL != "" && entry.Hash != "" {
+			c.entries[entry.URL] = entry
+		}
+	}
+}
+
+func (c *ImageCache) saveLocked() error {
+	entries := make([]*CacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].URL < entries[j].URL })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cache index")
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.dir, cacheIndexFile), data); err != nil {
+		return errors.Wrap(err, "failed to save cache index")
+	}
+	return nil
+}
+
+// writeFileAtomic 先写入临时文件再重命名，避免读到写了一半的文件
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
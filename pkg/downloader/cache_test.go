@@ -0,0 +1,270 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadImage_ContentAddressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/other" {
+			w.Write(pngImage("other"))
+			return
+		}
+		w.Write(pngImage("same"))
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(t)
+	ctx := context.Background()
+
+	a, err := d.DownloadImage(ctx, server.URL+"/a")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+	b, err := d.DownloadImage(ctx, server.URL+"/b?x=1")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+	other, err := d.DownloadImage(ctx, server.URL+"/other")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+
+	if a != b {
+		t.Errorf("same content should share one file: %s != %s", a, b)
+	}
+	if a == other {
+		t.Errorf("different content should not share a file")
+	}
+	if filepath.Dir(a) != filepath.Join(d.savePath, cacheObjectsDir) {
+		t.Errorf("image saved outside objects dir: %s", a)
+	}
+
+	stats := d.Cache().Stats()
+	if stats.Entries != 3 || stats.Objects != 2 {
+		t.Errorf("entries = %d, objects = %d, expected 3 and 2", stats.Entries, stats.Objects)
+	}
+	if want := int64(len(pngImage("same")) + len(pngImage("other"))); stats.TotalBytes != want {
+		t.Errorf("total bytes = %d, expected %d", stats.TotalBytes, want)
+	}
+}
+
+func TestDownloadImage_Revalidate(t *testing.T) {
+	var full, notModified int32
+	etag := `"v1"`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", etag)
+		w.Write(pngImage(etag))
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(t)
+	ctx := context.Background()
+
+	first, err := d.DownloadImage(ctx, server.URL+"/img")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+	second, err := d.DownloadImage(ctx, server.URL+"/img")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+	if first != second {
+		t.Errorf("revalidated image should reuse cached file")
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("full = %d, not modified = %d, expected 1 and 1", full, notModified)
+	}
+
+	// 内容变化后重新下载
+	etag = `"v2"`
+	third, err := d.DownloadImage(ctx, server.URL+"/img")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+	if third == first {
+		t.Errorf("changed image should be stored as a new object")
+	}
+
+	// 缓存文件被删除后重新下载，而不是返回不存在的路径
+	os.Remove(third)
+	fourth, err := d.DownloadImage(ctx, server.URL+"/img")
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+	if _, err := os.Stat(fourth); err != nil {
+		t.Errorf("image should be downloaded again: %v", err)
+	}
+}
+
+func TestImageCache_Eviction(t *testing.T) {
+	dir := t.TempDir()
+	c := newImageCache(dir)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	c.SetLimits(30, time.Hour)
+
+	put := func(url, content string) string {
+		t.Helper()
+		path, err := c.Put(url, []byte(content), "png", "", "")
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		return path
+	}
+
+	old := put("old", "0123456789")
+	now = now.Add(30 * time.Minute)
+	mid := put("mid", "abcdefghij")
+	now = now.Add(time.Minute)
+	c.Touch("old")
+
+	// 超过容量时删除最近最少使用的 mid，而不是刚被使用的 old
+	now = now.Add(time.Minute)
+	put("new", "ABCDEFGHIJKLMNO")
+	if _, _, ok := c.Lookup("mid"); ok {
+		t.Error("least recently used entry should be evicted")
+	}
+	if _, err := os.Stat(mid); !os.IsNotExist(err) {
+		t.Error("evicted image file should be removed")
+	}
+	if _, _, ok := c.Lookup("old"); !ok {
+		t.Error("recently used entry should be kept")
+	}
+
+	// 超过最长未使用时间后清理
+	now = now.Add(2 * time.Hour)
+	removed, freed, err := c.Evict()
+	if err != nil {
+		t.Fatalf("Evict: %v", err)
+	}
+	if removed != 2 || freed != 25 {
+		t.Errorf("removed = %d, freed = %d, expected 2 and 25", removed, freed)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expired image file should be removed")
+	}
+}
+
+func TestImageCache_SharedObject(t *testing.T) {
+	c := newImageCache(t.TempDir())
+	c.SetLimits(0, 0)
+
+	path, _ := c.Put("a", []byte("same"), "png", "", "")
+	c.Put("b", []byte("same"), "png", "", "")
+
+	// 其它地址仍引用同一份内容时不删除文件
+	c.removeLocked(func(e *CacheEntry) bool { return e.URL == "a" })
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("shared image file should be kept: %v", err)
+	}
+
+	removed, freed, err := c.Purge(0)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if removed != 1 || freed != 4 {
+		t.Errorf("removed = %d, freed = %d, expected 1 and 4", removed, freed)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("purged image file should be removed")
+	}
+}
+
+func TestImageCache_PinnedObjectsKept(t *testing.T) {
+	c := newImageCache(t.TempDir())
+	c.SetLimits(10, 0)
+
+	pinned, err := c.put("pinned", []byte("0123456789"), "png", "", "", true)
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	// 固定的图片在其它图片写入触发容量清理、以及清空缓存时都保留
+	if _, err := c.Put("other", []byte("abcdefghij"), "png", "", ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, _, err := c.Purge(0); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if _, err := os.Stat(pinned); err != nil {
+		t.Fatalf("pinned image file should be kept: %v", err)
+	}
+	if _, _, ok := c.Lookup("pinned"); !ok {
+		t.Error("pinned entry should be kept")
+	}
+
+	// 释放后可以被清理，不属于缓存的路径被忽略
+	c.Release(pinned, "/tmp/local.png")
+	if _, _, err := c.Purge(0); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if _, err := os.Stat(pinned); !os.IsNotExist(err) {
+		t.Error("released image file should be removed")
+	}
+}
+
+func TestImageCache_PurgeOlderThanAndPersistence(t *testing.T) {
+	dir := t.TempDir()
+	c := newImageCache(dir)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Put("stale", []byte("stale"), "jpg", `"e1"`, "")
+	now = now.Add(3 * time.Hour)
+	c.Put("fresh", []byte("fresh"), "jpg", "", "Mon, 03 Jun 2024 10:00:00 GMT")
+
+	// 旧版本遗留的文件只在清空时删除
+	legacy := filepath.Join(dir, "img_0123456789abcdef_1717000000.jpg")
+	os.WriteFile(legacy, []byte("legacy"), 0644)
+
+	removed, _, err := c.Purge(2 * time.Hour)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, expected 1", removed)
+	}
+
+	// 重新打开时从 index.json 恢复
+	reopened := newImageCache(dir)
+	entry, _, ok := reopened.Lookup("fresh")
+	if !ok {
+		t.Fatal("index should be persisted")
+	}
+	if entry.LastModified != "Mon, 03 Jun 2024 10:00:00 GMT" {
+		t.Errorf("last modified = %q", entry.LastModified)
+	}
+	if _, _, ok := reopened.Lookup("stale"); ok {
+		t.Error("purged entry should not be persisted")
+	}
+
+	if _, freed, _ := reopened.Purge(0); freed != int64(len("fresh")+len("legacy")) {
+		t.Errorf("freed = %d, expected %d", freed, len("fresh")+len("legacy"))
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("legacy image file should be removed")
+	}
+}
+
+func TestOpenImageCache_Shared(t *testing.T) {
+	dir := t.TempDir()
+	if OpenImageCache(dir) != OpenImageCache(dir+string(filepath.Separator)) {
+		t.Error("the same directory should share one cache")
+	}
+}
@@ -1,105 +1,292 @@
 package downloader
 
 import (
-	"crypto/sha256"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/h2non/filetype"
 	"github.com/pkg/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
 )
 
-// ImageDownloader 图片下载器
+const (
+	// DefaultMaxConcurrency 批量下载时同时进行的下载数
+	DefaultMaxConcurrency = 4
+	// DefaultMaxImageSize 单张图片的最大字节数
+	DefaultMaxImageSize = 20 << 20
+	// DefaultMaxRetries 临时性失败（网络错误、5xx、429）的最大重试次数
+	DefaultMaxRetries = 3
+	// defaultRetryBackoff 第一次重试前的等待时间，之后每次翻倍
+	defaultRetryBackoff = 500 * time.Millisecond
+)
+
+// ErrImageTooLarge 图片超过最大字节数
+var ErrImageTooLarge = errors.New("image exceeds maximum size")
+
+// ErrFileTooLarge 媒体文件超过最大字节数
+var ErrFileTooLarge = errors.New("file exceeds maximum size")
+
+// ImageDownloader 图片下载器，下载的图片保存在 savePath 下的内容寻址缓存中
 type ImageDownloader struct {
 	savePath   string
 	httpClient *http.Client
+	policy     URLPolicy
+	cache      *ImageCache // 首次下载图片时打开，只下载媒体文件时不需要
+	cacheOnce  sync.Once
+	pin        bool // 固定返回的缓存图片，使用完后需调用 Release
+
+	maxConcurrency int
+	maxBytes       int64
+	maxFileBytes   int64 // DownloadFile 单个文件的最大字节数
+	maxRetries     int
+	retryBackoff   time.Duration
 }
 
-// NewImageDownloader 创建图片下载器
+// retryableError 可以重试的临时性错误
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// NewImageDownloader 创建图片下载器，使用默认的地址访问限制
 func NewImageDownloader(savePath string) *ImageDownloader {
+	return NewImageDownloaderWithPolicy(savePath, DefaultURLPolicy())
+}
+
+// NewImageDownloaderWithPolicy 创建按 policy 限制下载地址的图片下载器
+func NewImageDownloaderWithPolicy(savePath string, policy URLPolicy) *ImageDownloader {
 	// 确保保存目录存在
 	if err := os.MkdirAll(savePath, 0755); err != nil {
 		panic(fmt.Sprintf("failed to create save path: %v", err))
 	}
 
 	return &ImageDownloader{
-		savePath: savePath,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		savePath:       savePath,
+		httpClient:     newHTTPClient(policy),
+		policy:         policy,
+		maxConcurrency: DefaultMaxConcurrency,
+		maxBytes:       DefaultMaxImageSize,
+		maxFileBytes:   configs.GetMediaMaxBytes(),
+		maxRetries:     DefaultMaxRetries,
+		retryBackoff:   defaultRetryBackoff,
 	}
 }
 
-// DownloadImage 下载图片
-// 返回本地文件路径
-func (d *ImageDownloader) DownloadImage(imageURL string) (string, error) {
+// Cache 返回下载器使用的图片缓存，默认为 savePath 下的缓存
+func (d *ImageDownloader) Cache() *ImageCache {
+	d.cacheOnce.Do(func() {
+		if d.cache == nil {
+			d.cache = OpenImageCache(d.savePath)
+		}
+	})
+	return d.cache
+}
+
+// DownloadImage 下载图片，临时性失败时按退避间隔重试
+// 已缓存的图片通过 ETag/Last-Modified 重新验证，未变化时直接使用缓存
+// 返回本地文件路径，开启固定时在 Release 之前该文件不会被缓存清理删除
+func (d *ImageDownloader) DownloadImage(ctx context.Context, imageURL string) (string, error) {
 	// 验证URL格式
 	if !d.isValidImageURL(imageURL) {
 		return "", errors.New("invalid image URL format")
 	}
+	if err := d.checkURL(imageURL); err != nil {
+		return "", err
+	}
+
+	cache := d.Cache()
+
+	// 重新验证期间固定已缓存的文件，避免返回 304 时文件已被其他请求清理
+	var cached *CacheEntry
+	entry, cachedPath, ok := cache.lookup(imageURL, d.pin)
+	if ok {
+		cached = &entry
+	}
+	releaseCached := func() {
+		if ok && d.pin {
+			cache.Release(cachedPath)
+		}
+	}
 
 	// 下载图片数据
-	resp, err := d.httpClient.Get(imageURL)
+	var result *fetchResult
+	err := d.withRetry(ctx, func() error {
+		r, err := d.fetch(ctx, imageURL, cached)
+		result = r
+		return err
+	})
 	if err != nil {
-		return "", errors.Wrap(err, "failed to download image")
+		releaseCached()
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	if result.notModified {
+		if err := cache.Touch(imageURL); err != nil {
+			releaseCached()
+			return "", err
+		}
+		return cachedPath, nil
 	}
 
-	// 读取图片数据
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to read image data")
-	}
+	// 内容已变化，新文件由 put 固定，旧文件不再使用
+	defer releaseCached()
 
 	// 检测图片格式
-	kind, err := filetype.Match(imageData)
+	kind, err := filetype.Match(result.data)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to detect file type")
 	}
 
-	if !filetype.IsImage(imageData) {
+	if !filetype.IsImage(result.data) {
 		return "", errors.New("downloaded file is not a valid image")
 	}
 
-	// 生成唯一文件名
-	fileName := d.generateFileName(imageURL, kind.Extension)
-	filePath := filepath.Join(d.savePath, fileName)
+	return cache.put(imageURL, result.data, kind.Extension, result.etag, result.lastModified, d.pin)
+}
+
+// fetchResult 一次下载请求的结果
+type fetchResult struct {
+	data         []byte
+	etag         string
+	lastModified string
+	notModified  bool // 服务端返回 304，缓存仍然有效
+}
+
+// fetch 发起一次下载请求并读取图片数据，超过 maxBytes 时返回 ErrImageTooLarge。
+// cached 不为空时带上条件请求头。
+func (d *ImageDownloader) fetch(ctx context.Context, imageURL string, cached *CacheEntry) (*fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		err = errors.Wrap(err, "failed to download image")
+		// 地址被拦截或重定向过多不是临时性错误，不重试
+		if errors.Is(err, ErrBlockedURL) || errors.Is(err, ErrTooManyRedirects) {
+			return nil, err
+		}
+		return nil, &retryableError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return &fetchResult{notModified: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("download failed with status: %d", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &retryableError{err}
+		}
+		return nil, err
+	}
 
-	// 如果文件已存在，直接返回路径
-	if _, err := os.Stat(filePath); err == nil {
-		return filePath, nil
+	// 优先按 Content-Length 拒绝，没有时在读取过程中限制
+	if resp.ContentLength > d.maxBytes {
+		return nil, errors.Wrapf(ErrImageTooLarge, "content length %d > %d", resp.ContentLength, d.maxBytes)
 	}
 
-	// 保存到文件
-	if err := os.WriteFile(filePath, imageData, 0644); err != nil {
-		return "", errors.Wrap(err, "failed to save image")
+	imageData, err := io.ReadAll(io.LimitReader(resp.Body, d.maxBytes+1))
+	if err != nil {
+		return nil, &retryableError{errors.Wrap(err, "failed to read image data")}
+	}
+	if int64(len(imageData)) > d.maxBytes {
+		return nil, errors.Wrapf(ErrImageTooLarge, "more than %d bytes", d.maxBytes)
 	}
 
-	return filePath, nil
+	return &fetchResult{
+		data:         imageData,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
 }
 
-// DownloadImages 批量下载图片
-func (d *ImageDownloader) DownloadImages(imageURLs []string) ([]string, error) {
+// withRetry 执行 fn，返回 retryableError 时按指数退避重试，最多重试 maxRetries 次
+func (d *ImageDownloader) withRetry(ctx context.Context, fn func() error) error {
+	backoff := d.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= d.maxRetries {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// DownloadImages 并发批量下载图片，同时进行的下载不超过 maxConcurrency 个。
+// 返回的路径与输入顺序一致，下载失败的图片被跳过并汇总到错误中。
+func (d *ImageDownloader) DownloadImages(ctx context.Context, imageURLs []string) ([]string, error) {
+	paths := make([]string, len(imageURLs))
+	downloadErrs := make([]error, len(imageURLs))
+
+	concurrency := d.maxConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, imageURL := range imageURLs {
+		wg.Add(1)
+		go func(i int, imageURL string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				downloadErrs[i] = ctx.Err()
+				return
+			}
+
+			paths[i], downloadErrs[i] = d.DownloadImage(ctx, imageURL)
+		}(i, imageURL)
+	}
+	wg.Wait()
+
 	var localPaths []string
 	var errs []error
-
-	for _, imageURL := range imageURLs {
-		localPath, err := d.DownloadImage(imageURL)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to download %s: %w", imageURL, err))
+	for i, imageURL := range imageURLs {
+		if downloadErrs[i] != nil {
+			errs = append(errs, fmt.Errorf("failed to download %s: %w", imageURL, downloadErrs[i]))
 			continue
 		}
-		localPaths = append(localPaths, localPath)
+		localPaths = append(localPaths, paths[i])
+	}
+
+	if err := ctx.Err(); err != nil {
+		return localPaths, errors.Wrap(err, "download canceled")
 	}
 
 	if len(errs) > 0 {
@@ -109,6 +296,15 @@ func (d *ImageDownloader) DownloadImages(imageURLs []string) ([]string, error) {
 	return localPaths, nil
 }
 
+// checkURL 按访问限制检查下载地址
+func (d *ImageDownloader) checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Wrap(err, "invalid URL")
+	}
+	return d.policy.CheckURL(u)
+}
+
 // isValidImageURL 检查是否为有效的图片URL
 func (d *ImageDownloader) isValidImageURL(rawURL string) bool {
 	// 检查是否以http/https开头
@@ -126,21 +322,6 @@ func (d *ImageDownloader) isValidImageURL(rawURL string) bool {
 	return parsedURL.Scheme != "" && parsedURL.Host != ""
 }
 
-// generateFileName 生成唯一的文件名
-func (d *ImageDownloader) generateFileName(imageURL, extension string) string {
-	// 使用URL的SHA256哈希作为文件名，确保唯一性
-	hash := sha256.Sum256([]byte(imageURL))
-	hashStr := fmt.Sprintf("%x", hash)
-
-	// 取前16位哈希值作为文件名
-	shortHash := hashStr[:16]
-
-	// 添加时间戳确保更好的唯一性
-	timestamp := time.Now().Unix()
-
-	return fmt.Sprintf("img_%s_%d.%s", shortHash, timestamp, extension)
-}
-
 // IsImageURL 判断字符串是否为图片URL
 func IsImageURL(path string) bool {
 	return strings.HasPrefix(strings.ToLower(path), "http://") ||
@@ -1,10 +1,19 @@
 package downloader
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestIsImageURL(t *testing.T) {
@@ -75,28 +84,198 @@ func TestImageDownloader_isValidImageURL(t *testing.T) {
 	}
 }
 
-func TestImageDownloader_generateFileName(t *testing.T) {
-	downloader := NewImageDownloader(os.TempDir())
+// newTestDownloader 创建允许访问本机 httptest 服务、重试间隔很短的下载器，便于测试
+func newTestDownloader(t *testing.T) *ImageDownloader {
+	d := NewImageDownloaderWithPolicy(t.TempDir(), URLPolicy{AllowPrivate: true})
+	d.retryBackoff = time.Millisecond
+	return d
+}
+
+// pngImage 返回以 PNG 文件头开始、内容各不相同的图片数据
+func pngImage(id string) []byte {
+	return append(append([]byte{}, pngHeader...), id...)
+}
+
+func TestDownloadImages_OrderAndConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
 
-	url := "https://example.com/image.jpg"
-	extension := "jpg"
+		// 越靠前的图片返回越慢，验证结果仍按输入顺序排列
+		i, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		time.Sleep(time.Duration(10-i) * 5 * time.Millisecond)
+		w.Write(pngImage(r.URL.Path))
+	}))
+	defer server.Close()
 
-	fileName1 := downloader.generateFileName(url, extension)
+	d := newTestDownloader(t)
+	d.maxConcurrency = 3
 
-	// 文件名应该包含扩展名
-	if filepath.Ext(fileName1) != "."+extension {
-		t.Errorf("fileName should end with .%s, got %s", extension, fileName1)
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", server.URL, i))
+	}
+
+	paths, err := d.DownloadImages(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("DownloadImages: %v", err)
+	}
+	if len(paths) != len(urls) {
+		t.Fatalf("got %d paths, expected %d", len(paths), len(urls))
+	}
+
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if !bytes.Equal(data, pngImage(fmt.Sprintf("/%d", i))) {
+			t.Errorf("paths[%d] does not contain image %d", i, i)
+		}
 	}
 
-	// 文件名应该包含img_前缀
-	if !strings.HasPrefix(filepath.Base(fileName1), "img_") {
-		t.Errorf("fileName should start with img_, got %s", fileName1)
+	if got := atomic.LoadInt32(&maxInFlight); got > 3 {
+		t.Errorf("max concurrent downloads = %d, expected <= 3", got)
 	}
+}
+
+func TestDownloadImages_PartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(pngImage(r.URL.Path))
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(t)
+
+	paths, err := d.DownloadImages(context.Background(), []string{server.URL + "/a", server.URL + "/missing", server.URL + "/b"})
+	if err == nil || !strings.Contains(err.Error(), "/missing") {
+		t.Errorf("expected error mentioning the failed URL, got %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("got %d paths, expected 2", len(paths))
+	}
+	data, _ := os.ReadFile(paths[1])
+	if !bytes.Equal(data, pngImage("/b")) {
+		t.Errorf("paths[1] should be image b")
+	}
+}
+
+func TestDownloadImage_SizeLimit(t *testing.T) {
+	big := append(pngImage("big"), make([]byte, 100)...)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// 不设置 Content-Length，分块返回
+			w.Write(big[:10])
+			w.(http.Flusher).Flush()
+			w.Write(big[10:])
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(big)))
+		w.Write(big)
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(t)
+	d.maxBytes = 64
 
-	// 不同URL应该生成不同的文件名
-	url2 := "https://example.com/different.jpg"
-	fileName2 := downloader.generateFileName(url2, extension)
-	if fileName1 == fileName2 {
-		t.Errorf("different URLs should generate different file names")
+	for _, path := range []string{"/content-length", "/chunked"} {
+		if _, err := d.DownloadImage(context.Background(), server.URL+path); !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("%s: expected ErrImageTooLarge, got %v", path, err)
+		}
+	}
+
+	d.maxBytes = int64(len(big))
+	if _, err := d.DownloadImage(context.Background(), server.URL+"/content-length"); err != nil {
+		t.Errorf("image at the size limit should succeed: %v", err)
+	}
+}
+
+func TestDownloadImage_Retry(t *testing.T) {
+	var hits int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/flaky":
+			if n <= 2 {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(pngImage("flaky"))
+		case "/down":
+			http.Error(w, "down", http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(t)
+
+	if _, err := d.DownloadImage(context.Background(), server.URL+"/flaky"); err != nil {
+		t.Errorf("flaky download should succeed after retries: %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("flaky: %d requests, expected 3", got)
+	}
+
+	atomic.StoreInt32(&hits, 0)
+	if _, err := d.DownloadImage(context.Background(), server.URL+"/down"); err == nil {
+		t.Error("expected error after exhausting retries")
+	}
+	if got := atomic.LoadInt32(&hits); got != DefaultMaxRetries+1 {
+		t.Errorf("down: %d requests, expected %d", got, DefaultMaxRetries+1)
+	}
+
+	// 4xx 不是临时性错误，不重试
+	atomic.StoreInt32(&hits, 0)
+	if _, err := d.DownloadImage(context.Background(), server.URL+"/missing"); err == nil {
+		t.Error("expected error for 404")
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("missing: %d requests, expected 1", got)
+	}
+}
+
+func TestDownloadImages_ContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	d := newTestDownloader(t)
+	d.maxConcurrency = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	paths, err := d.DownloadImages(ctx, []string{server.URL + "/1", server.URL + "/2", server.URL + "/3"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("got %d paths, expected none", len(paths))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation took %v", elapsed)
 	}
 }
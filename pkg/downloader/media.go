@@ -0,0 +1,104 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/h2non/filetype"
+	"github.com/pkg/errors"
+)
+
+// fileTypeHeaderSize filetype 识别文件类型所需的头部字节数
+const fileTypeHeaderSize = 262
+
+// SetMaxFileSize 设置 DownloadFile 单个文件的最大字节数
+func (d *ImageDownloader) SetMaxFileSize(maxBytes int64) {
+	d.maxFileBytes = maxBytes
+}
+
+// DownloadFile 下载图片或视频到保存目录，文件名为 baseName 加上按内容识别的扩展名。
+// 数据流式写入磁盘，下载完成后才出现在目标路径，超过 maxFileBytes 时返回 ErrFileTooLarge。
+// 返回本地文件路径和字节数。
+func (d *ImageDownloader) DownloadFile(ctx context.Context, fileURL, baseName string) (string, int64, error) {
+	if !d.isValidImageURL(fileURL) {
+		return "", 0, errors.New("invalid file URL format")
+	}
+	if err := d.checkURL(fileURL); err != nil {
+		return "", 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to create request")
+	}
+
+	// 视频文件较大，不使用客户端的整体超时，由 ctx 控制取消
+	client := *d.httpClient
+	client.Timeout = 0
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to download file")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > d.maxFileBytes {
+		return "", 0, errors.Wrapf(ErrFileTooLarge, "content length %d > %d", resp.ContentLength, d.maxFileBytes)
+	}
+
+	body := bufio.NewReaderSize(resp.Body, fileTypeHeaderSize)
+	header, _ := body.Peek(fileTypeHeaderSize)
+
+	filePath := filepath.Join(d.savePath, baseName+"."+fileExtension(header, fileURL))
+
+	tmp, err := os.CreateTemp(d.savePath, baseName+".*.part")
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to create file")
+	}
+	defer os.Remove(tmp.Name())
+
+	// 多读一个字节用于判断是否超过上限
+	size, err := io.Copy(tmp, io.LimitReader(body, d.maxFileBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to save file")
+	}
+	if size > d.maxFileBytes {
+		return "", 0, errors.Wrapf(ErrFileTooLarge, "more than %d bytes", d.maxFileBytes)
+	}
+
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return "", 0, errors.Wrap(err, "failed to save file")
+	}
+
+	return filePath, size, nil
+}
+
+// fileExtension 按文件头识别扩展名，无法识别时使用 URL 中的扩展名，都没有时为 bin
+func fileExtension(header []byte, fileURL string) string {
+	if kind, err := filetype.Match(header); err == nil && kind != filetype.Unknown {
+		return kind.Extension
+	}
+
+	if u, err := url.Parse(fileURL); err == nil {
+		if ext := strings.TrimPrefix(path.Ext(u.Path), "."); ext != "" {
+			return strings.ToLower(ext)
+		}
+	}
+
+	return "bin"
+}
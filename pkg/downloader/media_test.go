@@ -0,0 +1,100 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 最小的 PNG 文件头
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R'}
+
+func TestDownloadFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngHeader)
+	})
+	mux.HandleFunc("/clip.mov", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not a recognizable header"))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := NewImageDownloaderWithPolicy(dir, URLPolicy{AllowPrivate: true})
+
+	path, size, err := d.DownloadFile(context.Background(), server.URL+"/image", "image_01")
+	if err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if path != filepath.Join(dir, "image_01.png") {
+		t.Errorf("path = %q, expected image_01.png in %s", path, dir)
+	}
+	if size != int64(len(pngHeader)) {
+		t.Errorf("size = %d, expected %d", size, len(pngHeader))
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, pngHeader) {
+		t.Errorf("saved content mismatch")
+	}
+
+	// 无法识别文件头时使用 URL 中的扩展名
+	path, _, err = d.DownloadFile(context.Background(), server.URL+"/clip.mov", "video")
+	if err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if filepath.Base(path) != "video.mov" {
+		t.Errorf("path = %q, expected video.mov", path)
+	}
+
+	if _, _, err := d.DownloadFile(context.Background(), server.URL+"/missing", "missing"); err == nil {
+		t.Error("expected error for 404 response")
+	}
+
+	// 失败和完成的下载都不应留下临时文件
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected 2 files in save path, got %d", len(entries))
+	}
+}
+
+func TestDownloadFileTooLarge(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sized", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngHeader)
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		// 分块传输，没有 Content-Length，只能在读取时发现超限
+		w.Write(pngHeader[:8])
+		w.(http.Flusher).Flush()
+		w.Write(pngHeader[8:])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := NewImageDownloaderWithPolicy(dir, URLPolicy{AllowPrivate: true})
+	d.SetMaxFileSize(int64(len(pngHeader)) - 1)
+
+	for _, path := range []string{"/sized", "/chunked"} {
+		if _, _, err := d.DownloadFile(context.Background(), server.URL+path, "file"); !errors.Is(err, ErrFileTooLarge) {
+			t.Errorf("%s: expected ErrFileTooLarge, got %v", path, err)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no files after oversized downloads, got %d", len(entries))
+	}
+
+	d.SetMaxFileSize(int64(len(pngHeader)))
+	if _, _, err := d.DownloadFile(context.Background(), server.URL+"/chunked", "file"); err != nil {
+		t.Errorf("expected download at the limit to succeed, got %v", err)
+	}
+}
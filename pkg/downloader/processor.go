@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,28 +17,48 @@ type ImageProcessor struct {
 
 // NewImageProcessor 创建图片处理器
 func NewImageProcessor() *ImageProcessor {
+	d := NewImageDownloader(configs.GetImagesPath())
+	d.cache = DefaultImageCache()
+	// 发布期间下载的图片可能被其他请求的缓存清理删除，使用完后由 Release 释放
+	d.pin = true
+
 	return &ImageProcessor{
-		downloader: NewImageDownloader(configs.GetImagesPath()),
+		downloader: d,
 	}
 }
 
-// ProcessImages 处理图片列表，返回本地文件路径
+// DefaultImageCache 返回 URL 图片下载使用的缓存，容量和过期时间来自配置
+func DefaultImageCache() *ImageCache {
+	cache := OpenImageCache(configs.GetImagesPath())
+	cache.SetLimits(configs.GetImageCacheMaxBytes(), configs.GetImageCacheMaxAge())
+	return cache
+}
+
+// ProcessImages 处理图片列表，返回本地文件路径，顺序与输入一致。
+// 返回的图片使用完后需调用 Release，之前不会被缓存清理删除
 // 支持两种输入格式：
 // 1. URL格式 (http/https开头) - 自动下载到本地
 // 2. 本地文件路径 - 验证后使用
-func (p *ImageProcessor) ProcessImages(images []string) ([]string, error) {
-	var localPaths []string
+func (p *ImageProcessor) ProcessImages(ctx context.Context, images []string) ([]string, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no valid images found")
+	}
+
+	// 按输入位置填充结果，封面序号等依赖顺序的参数才能对应到正确的图片
+	result := make([]string, len(images))
 	var urlsToDownload []string
+	var urlIndexes []int
 	var invalidPaths []string
 
 	// 分离URL和本地路径
-	for _, image := range images {
+	for i, image := range images {
 		if IsImageURL(image) {
 			urlsToDownload = append(urlsToDownload, image)
+			urlIndexes = append(urlIndexes, i)
 		} else {
 			// 验证本地路径
 			if isValidLocalPath(image) {
-				localPaths = append(localPaths, image)
+				result[i] = image
 			} else {
 				invalidPaths = append(invalidPaths, image)
 			}
@@ -49,20 +70,24 @@ func (p *ImageProcessor) ProcessImages(images []string) ([]string, error) {
 		return nil, fmt.Errorf("invalid local file paths (file not found or not an image): %v", invalidPaths)
 	}
 
-	// 批量下载URL图片
+	// 批量下载URL图片，下载结果与 urlsToDownload 顺序一致
 	if len(urlsToDownload) > 0 {
-		downloadedPaths, err := p.downloader.DownloadImages(urlsToDownload)
+		downloadedPaths, err := p.downloader.DownloadImages(ctx, urlsToDownload)
 		if err != nil {
+			p.Release(downloadedPaths)
 			return nil, fmt.Errorf("failed to download images: %w", err)
 		}
-		localPaths = append(localPaths, downloadedPaths...)
+		for j, path := range downloadedPaths {
+			result[urlIndexes[j]] = path
+		}
 	}
 
-	if len(localPaths) == 0 {
-		return nil, fmt.Errorf("no valid images found")
-	}
+	return result, nil
+}
 
-	return localPaths, nil
+// Release 释放 ProcessImages 返回的图片，本地路径会被忽略
+func (p *ImageProcessor) Release(paths []string) {
+	p.downloader.Cache().Release(paths...)
 }
 
 // isValidLocalPath 验证本地文件路径是否有效
@@ -0,0 +1,52 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessImages_MixedInputOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngImage(r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	localA := filepath.Join(dir, "a.png")
+	localB := filepath.Join(dir, "b.png")
+	for _, path := range []string{localA, localB} {
+		if err := os.WriteFile(path, pngImage(path), 0644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	p := &ImageProcessor{downloader: newTestDownloader(t)}
+	images := []string{server.URL + "/first", localA, server.URL + "/third", localB}
+
+	paths, err := p.ProcessImages(context.Background(), images)
+	if err != nil {
+		t.Fatalf("ProcessImages: %v", err)
+	}
+	if len(paths) != len(images) {
+		t.Fatalf("got %d paths, expected %d", len(paths), len(images))
+	}
+
+	// 本地路径原样返回，URL 替换为下载后的文件，位置与输入一致
+	if paths[1] != localA || paths[3] != localB {
+		t.Errorf("local paths moved: %v", paths)
+	}
+	for i, id := range map[int]string{0: "/first", 2: "/third"} {
+		data, err := os.ReadFile(paths[i])
+		if err != nil {
+			t.Fatalf("read %s: %v", paths[i], err)
+		}
+		if !bytes.Equal(data, pngImage(id)) {
+			t.Errorf("paths[%d] does not contain image %s", i, id)
+		}
+	}
+}
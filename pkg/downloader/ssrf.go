@@ -0,0 +1,160 @@
+package downloader
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xpzouying/xiaohongshu-mcp/configs"
+)
+
+// maxRedirects 下载时最多跟随的重定向次数
+const maxRedirects = 5
+
+var (
+	// ErrBlockedURL 地址指向内网、本机或链路本地地址，或不在域名白名单中
+	ErrBlockedURL = errors.New("url is not allowed")
+	// ErrTooManyRedirects 重定向次数超过 maxRedirects
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// 除标准库已能识别的回环、私有、链路本地、组播地址外，同样不允许访问的网段
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),   // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"),   // 网络基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),     // 保留地址及受限广播
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64，可映射到任意 IPv4 地址
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // 本地 NAT64
+	netip.MustParsePrefix("2002::/16"),       // 6to4，可嵌入任意 IPv4 地址
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4 转换地址
+	netip.MustParsePrefix("fec0::/10"),       // 已废弃的站点本地地址
+	netip.MustParsePrefix("2001:db8::/32"),   // 文档示例
+	netip.MustParsePrefix("100::/64"),        // 丢弃前缀
+	netip.MustParsePrefix("2001::/23"),       // IETF 协议分配（含 Teredo）
+}
+
+// URLPolicy 下载地址的访问限制
+type URLPolicy struct {
+	// AllowedHosts 域名白名单，同时匹配子域名，为空时不限制域名
+	AllowedHosts []string
+	// AllowPrivate 允许访问内网和本机地址，仅用于测试
+	AllowPrivate bool
+}
+
+// DefaultURLPolicy 返回默认的访问限制：禁止内网地址，域名白名单来自配置
+func DefaultURLPolicy() URLPolicy {
+	return URLPolicy{AllowedHosts: configs.GetImageHostAllowlist()}
+}
+
+// CheckURL 检查地址的协议、域名白名单，以及直接写在地址中的 IP。
+// 域名解析后的地址在建立连接时检查。
+func (p URLPolicy) CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Wrapf(ErrBlockedURL, "unsupported scheme %q", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if !p.AllowPrivate {
+		if addr, err := netip.ParseAddr(host); err == nil && isBlockedAddr(addr) {
+			return errors.Wrapf(ErrBlockedURL, "address %s is private, loopback or link-local", host)
+		}
+	}
+
+	if len(p.AllowedHosts) > 0 && !p.hostAllowed(host) {
+		return errors.Wrapf(ErrBlockedURL, "host %s is not in the allowlist", host)
+	}
+
+	return nil
+}
+
+func (p URLPolicy) hostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(strings.Trim(allowed, "."))
+		if allowed == "" {
+			continue
+		}
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockedAddr 判断地址是否为内网、本机、链路本地等不允许访问的地址
+func isBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDialAddress 作为 net.Dialer.Control，在每次建立连接前检查 DNS 解析后的实际地址，
+// 重定向和 DNS 重绑定都无法绕过
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrapf(ErrBlockedURL, "invalid address %s", address)
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return errors.Wrapf(ErrBlockedURL, "invalid address %s", address)
+	}
+
+	if isBlockedAddr(addr) {
+		return errors.Wrapf(ErrBlockedURL, "address %s is private, loopback or link-local", host)
+	}
+	return nil
+}
+
+// newHTTPClient 创建按 policy 限制访问地址的 HTTP 客户端
+func newHTTPClient(policy URLPolicy) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !policy.AllowPrivate {
+		dialer.Control = checkDialAddress
+	}
+
+	transport := &http.Transport{
+		// 不使用环境变量中的代理：经过代理时实际连接的是代理地址，无法检查目标地址
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.Wrapf(ErrTooManyRedirects, "stopped after %d redirects", maxRedirects)
+			}
+			return policy.CheckURL(req.URL)
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsBlockedAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"127.1.2.3", true},
+		{"10.0.0.1", true},
+		{"172.16.5.4", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true}, // 云服务器元数据地址
+		{"100.100.100.200", true}, // 阿里云元数据地址（运营商级 NAT 网段）
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		{"::1", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true}, // AWS IPv6 元数据地址
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:169.254.169.254", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"8.8.8.8", false},
+		{"223.5.5.5", false},
+		{"2001:4860:4860::8888", false},
+	}
+
+	for _, tt := range tests {
+		if got := isBlockedAddr(netip.MustParseAddr(tt.addr)); got != tt.blocked {
+			t.Errorf("isBlockedAddr(%s) = %v, expected %v", tt.addr, got, tt.blocked)
+		}
+	}
+}
+
+func TestURLPolicy_CheckURL(t *testing.T) {
+	allowlist := URLPolicy{AllowedHosts: []string{"xhscdn.com", ".example.com"}}
+
+	tests := []struct {
+		policy  URLPolicy
+		url     string
+		allowed bool
+	}{
+		{URLPolicy{}, "https://sns-webpic-qc.xhscdn.com/a.jpg", true},
+		{URLPolicy{}, "http://127.0.0.1:8080/a.jpg", false},
+		{URLPolicy{}, "http://[::1]/a.jpg", false},
+		{URLPolicy{}, "http://169.254.169.254/latest/meta-data/", false},
+		{URLPolicy{}, "file:///etc/passwd", false},
+		{URLPolicy{AllowPrivate: true}, "http://127.0.0.1:8080/a.jpg", true},
+		{allowlist, "https://sns-webpic-qc.xhscdn.com/a.jpg", true},
+		{allowlist, "https://XHSCDN.COM./a.jpg", true},
+		{allowlist, "https://img.example.com/a.jpg", true},
+		{allowlist, "https://evilxhscdn.com/a.jpg", false},
+		{allowlist, "https://xhscdn.com.evil.com/a.jpg", false},
+		{allowlist, "https://other.com/a.jpg", false},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.url, err)
+		}
+		err = tt.policy.CheckURL(u)
+		if tt.allowed && err != nil {
+			t.Errorf("CheckURL(%s) = %v, expected allowed", tt.url, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrBlockedURL) {
+			t.Errorf("CheckURL(%s) = %v, expected ErrBlockedURL", tt.url, err)
+		}
+	}
+}
+
+func TestDownload_BlocksPrivateAddresses(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write(pngImage("secret"))
+	}))
+	defer server.Close()
+
+	d := NewImageDownloaderWithPolicy(t.TempDir(), URLPolicy{})
+	ctx := context.Background()
+
+	// 地址中直接写 IP
+	if _, err := d.DownloadImage(ctx, server.URL+"/a.png"); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("loopback IP: expected ErrBlockedURL, got %v", err)
+	}
+
+	// 域名解析到本机地址，在建立连接时拦截
+	localhost := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if _, err := d.DownloadImage(ctx, localhost+"/a.png"); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("localhost: expected ErrBlockedURL, got %v", err)
+	}
+	if _, _, err := d.DownloadFile(ctx, localhost+"/video", "video"); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("localhost video: expected ErrBlockedURL, got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&hits); got != 0 {
+		t.Errorf("blocked requests reached the server %d times", got)
+	}
+}
+
+func TestDownload_BlocksRedirects(t *testing.T) {
+	var internalHits int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&internalHits, 1)
+		w.Write(pngImage("internal"))
+	}))
+	defer internal.Close()
+
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/internal":
+			http.Redirect(w, r, strings.Replace(internal.URL, "127.0.0.1", "localhost", 1)+"/a.png", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer public.Close()
+
+	// 两个测试服务都在本机，用白名单模拟“只允许公网服务”的效果：
+	// 允许 127.0.0.1 的 public，不允许 localhost 的 internal
+	d := NewImageDownloaderWithPolicy(t.TempDir(), URLPolicy{AllowPrivate: true, AllowedHosts: []string{"127.0.0.1"}})
+	ctx := context.Background()
+
+	if _, err := d.DownloadImage(ctx, public.URL+"/internal"); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("redirect to internal host: expected ErrBlockedURL, got %v", err)
+	}
+	if got := atomic.LoadInt32(&internalHits); got != 0 {
+		t.Errorf("redirect reached the internal server %d times", got)
+	}
+
+	if _, err := d.DownloadImage(ctx, public.URL+"/loop"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("redirect loop: expected redirect limit error, got %v", err)
+	}
+
+	// 禁止内网地址时，重定向到元数据地址在跟随前被拦截
+	client := newHTTPClient(URLPolicy{})
+	req, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data/", nil)
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://img.example.com/a.png", nil)}
+	if err := client.CheckRedirect(req, via); !errors.Is(err, ErrBlockedURL) {
+		t.Errorf("redirect to metadata address: expected ErrBlockedURL, got %v", err)
+	}
+}
+
+func TestCheckDialAddress(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:80", "[::1]:443", "10.1.2.3:8080", "169.254.169.254:80", "not-an-address"} {
+		if err := checkDialAddress("tcp", address, nil); !errors.Is(err, ErrBlockedURL) {
+			t.Errorf("checkDialAddress(%s) = %v, expected ErrBlockedURL", address, err)
+		}
+	}
+	if err := checkDialAddress("tcp", "8.8.8.8:443", nil); err != nil {
+		t.Errorf("checkDialAddress(8.8.8.8:443) = %v, expected nil", err)
+	}
+}
@@ -0,0 +1,224 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInProgress 相同幂等键的请求仍在执行中
+	ErrInProgress = errors.New("相同幂等键的请求正在执行中")
+	// ErrKeyReused 幂等键已被内容不同的请求使用
+	ErrKeyReused = errors.New("幂等键已被其他内容的请求使用")
+)
+
+// PendingTTL pending 记录的有效期。
+// pending 表示尚未点击发布按钮，进程崩溃或重启遗留的 pending 记录超过该时间后删除，相同键可以重试。
+const PendingTTL = 30 * time.Minute
+
+// Status 记录状态
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSubmitted Status = "submitted" // 已点击发布按钮，结果未知，有效期内不再重复发布
+	StatusSucceeded Status = "succeeded"
+)
+
+// Record 幂等记录
+type Record struct {
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"`
+	Status      Status          `json:"status"`
+	NoteID      string          `json:"note_id,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Store 基于本地 JSON 文件的幂等记录存储，path 为空时仅保存在内存中
+type Store struct {
+	path    string
+	window  time.Duration
+	mu      sync.Mutex
+	records map[string]*Record
+	now     func() time.Time
+}
+
+// NewStore 创建幂等存储并加载已有记录，超出 window 的记录会被丢弃
+func NewStore(path string, window time.Duration) (*Store, error) {
+	s := &Store{
+		path:    path,
+		window:  window,
+		records: make(map[string]*Record),
+		now:     time.Now,
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, errors.Wrap(err, "读取幂等记录失败")
+	}
+
+	var records []*Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, errors.Wrap(err, "解析幂等记录失败")
+	}
+
+	for _, r := range records {
+		s.records[r.Key] = r
+	}
+	// 进程重启前遗留的 pending 记录尚未点击发布，超过 PendingTTL 后删除；submitted 记录保留至有效期结束
+	s.pruneLocked()
+
+	return s, nil
+}
+
+// Fingerprint 根据请求内容生成指纹，用于识别同一幂等键下的不同请求
+func Fingerprint(v any) string {
+	data, _ := json.Marshal(v)
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// Begin 开始一次幂等请求。
+// 若有效期内已存在相同键的已提交或已完成记录则返回该记录且 started 为 false；
+// 否则创建 pending 记录并返回 started 为 true，调用方需随后调用 Complete 或 Release，
+// 点击发布按钮前调用 MarkSubmitted。
+func (s *Store) Begin(key, fingerprint string) (record *Record, started bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+
+	if existing, ok := s.records[key]; ok {
+		if existing.Fingerprint != fingerprint {
+			return nil, false, ErrKeyReused
+		}
+		if existing.Status == StatusPending {
+			return nil, false, ErrInProgress
+		}
+		copied := *existing
+		return &copied, false, nil
+	}
+
+	now := s.now()
+	s.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      StatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	return nil, true, s.saveLocked()
+}
+
+// Complete 保存请求结果，noteID 可在后续得知后再次调用更新
+func (s *Store) Complete(key string, result any, noteID string) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errors.Wrap(err, "序列化幂等结果失败")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return errors.Errorf("幂等记录不存在: %s", key)
+	}
+
+	record.Status = StatusSucceeded
+	record.Result = data
+	if noteID != "" {
+		record.NoteID = noteID
+	}
+	record.UpdatedAt = s.now()
+
+	return s.saveLocked()
+}
+
+// MarkSubmitted 在点击发布按钮前调用，之后即使请求失败也不再释放该键，
+// 避免发布结果未知时客户端重试导致重复发布
+func (s *Store) MarkSubmitted(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return errors.Errorf("幂等记录不存在: %s", key)
+	}
+
+	if record.Status == StatusPending {
+		record.Status = StatusSubmitted
+		record.UpdatedAt = s.now()
+	}
+
+	return s.saveLocked()
+}
+
+// Release 删除尚未点击发布的记录，使相同键的请求可以重试
+func (s *Store) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && record.Status == StatusPending {
+		delete(s.records, key)
+		return s.saveLocked()
+	}
+
+	return nil
+}
+
+// pruneLocked 删除超出有效期的记录，以及超过 PendingTTL 仍未点击发布的 pending 记录
+func (s *Store) pruneLocked() {
+	now := s.now()
+	for key, r := range s.records {
+		expired := s.window > 0 && r.CreatedAt.Before(now.Add(-s.window))
+		stalePending := r.Status == StatusPending && r.UpdatedAt.Before(now.Add(-PendingTTL))
+		if expired || stalePending {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	records := make([]*Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "序列化幂等记录失败")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return errors.Wrap(err, "创建幂等记录目录失败")
+	}
+
+	// 先写临时文件再重命名，避免进程中断导致文件损坏
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrap(err, "写入幂等记录失败")
+	}
+
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,163 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type result struct {
+	Title  string `json:"title"`
+	PostID string `json:"post_id"`
+}
+
+func TestStore_BeginComplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+
+	store, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp := Fingerprint(map[string]string{"title": "hello"})
+
+	record, started, err := store.Begin("key-1", fp)
+	if err != nil || !started || record != nil {
+		t.Fatalf("first Begin: record=%v started=%v err=%v", record, started, err)
+	}
+
+	if _, _, err := store.Begin("key-1", fp); !errors.Is(err, ErrInProgress) {
+		t.Fatalf("expected ErrInProgress, got %v", err)
+	}
+
+	if err := store.Complete("key-1", result{Title: "hello", PostID: "abc"}, "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新加载，验证记录已持久化
+	reloaded, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record, started, err = reloaded.Begin("key-1", fp)
+	if err != nil || started || record == nil {
+		t.Fatalf("repeat Begin: record=%v started=%v err=%v", record, started, err)
+	}
+
+	if record.NoteID != "abc" || record.Status != StatusSucceeded {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	var got result
+	if err := json.Unmarshal(record.Result, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "hello" || got.PostID != "abc" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+
+	if _, _, err := reloaded.Begin("key-1", Fingerprint("other")); !errors.Is(err, ErrKeyReused) {
+		t.Fatalf("expected ErrKeyReused, got %v", err)
+	}
+}
+
+func TestStore_Release(t *testing.T) {
+	store, err := NewStore("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, started, _ := store.Begin("key", "fp"); !started {
+		t.Fatal("expected started")
+	}
+
+	if err := store.Release("key"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, started, err := store.Begin("key", "fp"); err != nil || !started {
+		t.Fatalf("expected retry to start after release, started=%v err=%v", started, err)
+	}
+}
+
+func TestStore_Expiry(t *testing.T) {
+	store, err := NewStore("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	store.Begin("key", "fp")
+	if err := store.Complete("key", result{Title: "old"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	store.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	if _, started, err := store.Begin("key", "fp"); err != nil || !started {
+		t.Fatalf("expected expired key to start again, started=%v err=%v", started, err)
+	}
+}
+
+func TestStore_StalePendingExpires(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+
+	store, err := NewStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if _, started, _ := store.Begin("pending", "fp"); !started {
+		t.Fatal("expected started")
+	}
+	if _, started, _ := store.Begin("submitted", "fp"); !started {
+		t.Fatal("expected started")
+	}
+	if err := store.MarkSubmitted("submitted"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟进程崩溃后重启，pending 记录超过 PendingTTL
+	reloaded, err := NewStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded.now = func() time.Time { return now.Add(PendingTTL + time.Minute) }
+
+	if _, started, err := reloaded.Begin("pending", "fp"); err != nil || !started {
+		t.Fatalf("expected stale pending key to start again, started=%v err=%v", started, err)
+	}
+
+	record, started, err := reloaded.Begin("submitted", "fp")
+	if err != nil || started || record == nil || record.Status != StatusSubmitted {
+		t.Fatalf("expected submitted record to be kept, record=%+v started=%v err=%v", record, started, err)
+	}
+}
+
+func TestStore_ReleaseAfterSubmit(t *testing.T) {
+	store, err := NewStore("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Begin("key", "fp")
+	if err := store.MarkSubmitted("key"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已点击发布的记录不能被释放
+	if err := store.Release("key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, started, err := store.Begin("key", "fp"); err != nil || started {
+		t.Fatalf("expected submitted key to be kept, started=%v err=%v", started, err)
+	}
+}
@@ -0,0 +1,246 @@
+// Package imaging 在上传前统一处理图片：解码、按 EXIF 方向摆正、去除元数据、
+// 调整宽高比和尺寸，最后编码为 JPEG。
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// DefaultMaxDimension 处理后图片最长边的默认像素上限
+	DefaultMaxDimension = 2560
+	// DefaultQuality 默认 JPEG 编码质量
+	DefaultQuality = 90
+
+	// MinDimension、MaxDimension 允许设置的最长边上限范围
+	MinDimension = 100
+	MaxDimension = 10000
+
+	// maxSourcePixels 解码前拒绝像素数过大的图片，避免解码时占用过多内存
+	maxSourcePixels = 100_000_000
+)
+
+// ErrUnsupportedImage 图片无法解码（格式不支持或文件损坏）
+var ErrUnsupportedImage = errors.New("unsupported or corrupt image")
+
+// Aspect 目标宽高比
+type Aspect string
+
+const (
+	AspectOriginal  Aspect = ""    // 保持原比例
+	AspectPortrait  Aspect = "3:4" // 竖图，小红书推荐比例
+	AspectSquare    Aspect = "1:1"
+	AspectLandscape Aspect = "4:3"
+)
+
+// aspectRatios 宽高比对应的宽、高
+var aspectRatios = map[Aspect][2]int{
+	AspectPortrait:  {3, 4},
+	AspectSquare:    {1, 1},
+	AspectLandscape: {4, 3},
+}
+
+// ParseAspect 解析宽高比，空字符串和 original 表示保持原比例
+func ParseAspect(s string) (Aspect, error) {
+	a := Aspect(strings.TrimSpace(s))
+	if a == AspectOriginal || strings.EqualFold(string(a), "original") {
+		return AspectOriginal, nil
+	}
+
+	if _, ok := aspectRatios[a]; !ok {
+		return "", errors.Errorf("不支持的图片比例: %s（可选 original、3:4、1:1、4:3）", s)
+	}
+
+	return a, nil
+}
+
+// Fit 调整宽高比的方式
+type Fit string
+
+const (
+	FitCrop Fit = "crop" // 居中裁剪
+	FitPad  Fit = "pad"  // 白色留白填充，保留完整画面
+)
+
+// ParseFit 解析调整宽高比的方式，空字符串视为 crop
+func ParseFit(s string) (Fit, error) {
+	f := Fit(strings.ToLower(strings.TrimSpace(s)))
+	switch f {
+	case "":
+		return FitCrop, nil
+	case FitCrop, FitPad:
+		return f, nil
+	}
+	return "", errors.Errorf("不支持的比例调整方式: %s（可选 crop、pad）", s)
+}
+
+// Options 图片处理参数
+type Options struct {
+	MaxDimension int    // 最长边像素上限，只缩小不放大，0 时使用 DefaultMaxDimension
+	Aspect       Aspect // 目标宽高比，为空时保持原比例
+	Fit          Fit    // 调整宽高比的方式，为空时居中裁剪
+	Quality      int    // JPEG 质量 1-100，0 时使用 DefaultQuality
+}
+
+// Validate 检查参数范围
+func (o Options) Validate() error {
+	if o.MaxDimension != 0 && (o.MaxDimension < MinDimension || o.MaxDimension > MaxDimension) {
+		return errors.Errorf("图片最长边需在%d到%d像素之间，当前%d", MinDimension, MaxDimension, o.MaxDimension)
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return errors.Errorf("JPEG 质量需在1到100之间，当前%d", o.Quality)
+	}
+	if _, err := ParseAspect(string(o.Aspect)); err != nil {
+		return err
+	}
+	if _, err := ParseFit(string(o.Fit)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxDimension == 0 {
+		o.MaxDimension = DefaultMaxDimension
+	}
+	if o.Quality == 0 {
+		o.Quality = DefaultQuality
+	}
+	if o.Fit == "" {
+		o.Fit = FitCrop
+	}
+	return o
+}
+
+// Normalize 处理图片数据并返回 JPEG 编码结果。
+// 重新编码后不再包含 EXIF、ICC 等元数据；透明区域以白色填充。
+func Normalize(data []byte, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrUnsupportedImage, err.Error())
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxSourcePixels {
+		return nil, errors.Errorf("image is too large to process: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrUnsupportedImage, err.Error())
+	}
+
+	img := orient(flatten(src), readOrientation(data))
+	img = fitAspect(img, opts.Aspect, opts.Fit)
+	img = resize(img, opts.MaxDimension)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: opts.Quality}); err != nil {
+		return nil, errors.Wrap(err, "failed to encode jpeg")
+	}
+	return buf.Bytes(), nil
+}
+
+// NormalizeFile 处理 src 指向的图片，结果写入 dst
+func NormalizeFile(src, dst string, opts Options) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return errors.Wrap(err, "failed to read image")
+	}
+
+	out, err := Normalize(data, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to process %s", src)
+	}
+
+	if err := os.WriteFile(dst, out, 0644); err != nil {
+		return errors.Wrap(err, "failed to write image")
+	}
+	return nil
+}
+
+// flatten 将图片绘制到白色背景上，去掉透明通道
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// fitAspect 按 fit 将图片裁剪或填充为目标宽高比
+func fitAspect(img *image.RGBA, aspect Aspect, fit Fit) *image.RGBA {
+	ratio, ok := aspectRatios[aspect]
+	if !ok {
+		return img
+	}
+	aw, ah := ratio[0], ratio[1]
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	if w*ah == h*aw {
+		return img
+	}
+	// 比目标比例更宽时调整高度（填充）或宽度（裁剪），否则相反
+	tooWide := w*ah > h*aw
+
+	if fit == FitPad {
+		tw, th := w, h
+		if tooWide {
+			th = (w*ah + aw - 1) / aw
+		} else {
+			tw = (h*aw + ah - 1) / ah
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		offset := image.Pt((tw-w)/2, (th-h)/2)
+		draw.Draw(dst, img.Bounds().Add(offset), img, image.Point{}, draw.Src)
+		return dst
+	}
+
+	tw, th := w, h
+	if tooWide {
+		tw = max(1, h*aw/ah)
+	} else {
+		th = max(1, w*ah/aw)
+	}
+
+	x0, y0 := (w-tw)/2, (h-th)/2
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x0, y0), draw.Src)
+	return dst
+}
+
+// resize 等比缩小图片使最长边不超过 maxDimension，不放大
+func resize(img *image.RGBA, maxDimension int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= maxDimension && h <= maxDimension {
+		return img
+	}
+
+	tw, th := maxDimension, maxDimension
+	if w >= h {
+		th = max(1, h*maxDimension/w)
+	} else {
+		tw = max(1, w*maxDimension/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
@@ -0,0 +1,340 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAspect(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Aspect
+		wantErr bool
+	}{
+		{"", AspectOriginal, false},
+		{"original", AspectOriginal, false},
+		{" 3:4 ", AspectPortrait, false},
+		{"1:1", AspectSquare, false},
+		{"4:3", AspectLandscape, false},
+		{"16:9", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseAspect(test.input)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseAspect(%q) = %q, %v", test.input, got, err)
+		}
+	}
+}
+
+func TestParseFit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Fit
+		wantErr bool
+	}{
+		{"", FitCrop, false},
+		{"crop", FitCrop, false},
+		{"PAD", FitPad, false},
+		{"stretch", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseFit(test.input)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseFit(%q) = %q, %v", test.input, got, err)
+		}
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	valid := []Options{
+		{},
+		{MaxDimension: 1080, Aspect: AspectPortrait, Fit: FitPad, Quality: 85},
+	}
+	for _, opts := range valid {
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", opts, err)
+		}
+	}
+
+	invalid := []Options{
+		{MaxDimension: 50},
+		{MaxDimension: 20000},
+		{Quality: 101},
+		{Aspect: "2:1"},
+		{Fit: "stretch"},
+	}
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", opts)
+		}
+	}
+}
+
+// numbered 返回每个像素的 R 通道为其序号的图片，便于检查像素位置
+func numbered(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(y*w + x), A: 255})
+		}
+	}
+	return img
+}
+
+func TestOrient(t *testing.T) {
+	// 原图 3x2：
+	// 0 1 2
+	// 3 4 5
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{2, [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{3, [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{4, [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{5, [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{6, [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{7, [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{8, [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+	}
+
+	for _, test := range tests {
+		got := orient(numbered(3, 2), test.orientation)
+		if got.Bounds().Dy() != len(test.want) || got.Bounds().Dx() != len(test.want[0]) {
+			t.Errorf("orientation %d: size %v", test.orientation, got.Bounds().Size())
+			continue
+		}
+		for y, row := range test.want {
+			for x, want := range row {
+				if r := got.RGBAAt(x, y).R; r != want {
+					t.Errorf("orientation %d: pixel (%d,%d) = %d, want %d", test.orientation, x, y, r, want)
+				}
+			}
+		}
+	}
+}
+
+// exifTIFF 返回只包含方向标签的 TIFF 数据
+func exifTIFF(order binary.ByteOrder, orientation uint16) []byte {
+	buf := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(buf, "II")
+	} else {
+		copy(buf, "MM")
+	}
+	order.PutUint16(buf[2:], 42)
+	order.PutUint32(buf[4:], 8)
+	order.PutUint16(buf[8:], 1)
+	order.PutUint16(buf[10:], exifOrientationTag)
+	order.PutUint16(buf[12:], 3)
+	order.PutUint32(buf[14:], 1)
+	order.PutUint16(buf[18:], orientation)
+	return buf
+}
+
+// jpegWithOrientation 编码 w x h 的 JPEG，并插入带方向标签的 APP1 段
+func jpegWithOrientation(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	payload := append(append([]byte{}, exifHeader...), exifTIFF(binary.BigEndian, orientation)...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestReadOrientation(t *testing.T) {
+	if got := readOrientation(jpegWithOrientation(t, 4, 4, 6)); got != 6 {
+		t.Errorf("jpeg orientation = %d, want 6", got)
+	}
+
+	// PNG eXIf 块插入在 IHDR 之后
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	ihdrEnd := len(pngSignature) + 8 + 13 + 4
+	tiff := exifTIFF(binary.LittleEndian, 8)
+	chunk := make([]byte, 8)
+	binary.BigEndian.PutUint32(chunk, uint32(len(tiff)))
+	copy(chunk[4:], "eXIf")
+	chunk = append(chunk, tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	withExif := append(append(append([]byte{}, data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+	if got := readOrientation(withExif); got != 8 {
+		t.Errorf("png orientation = %d, want 8", got)
+	}
+	if _, err := png.Decode(bytes.NewReader(withExif)); err != nil {
+		t.Errorf("png with eXIf chunk is invalid: %v", err)
+	}
+
+	if got := readOrientation(data); got != 1 {
+		t.Errorf("png without exif orientation = %d, want 1", got)
+	}
+	if got := readOrientation([]byte("not an image")); got != 1 {
+		t.Errorf("unknown data orientation = %d, want 1", got)
+	}
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+	if format != "jpeg" {
+		t.Fatalf("output format = %s, want jpeg", format)
+	}
+	return img
+}
+
+func TestNormalizeAutoOrientsAndStripsExif(t *testing.T) {
+	out, err := Normalize(jpegWithOrientation(t, 400, 200, 6), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := decodeJPEG(t, out)
+	if size := img.Bounds().Size(); size != image.Pt(200, 400) {
+		t.Errorf("size = %v, want 200x400", size)
+	}
+	if bytes.Contains(out, exifHeader) {
+		t.Error("output still contains exif data")
+	}
+}
+
+func TestNormalizeFlattensAlpha(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 200, 200))); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Normalize(buf.Bytes(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, g, b, _ := decodeJPEG(t, out).At(100, 100).RGBA()
+	if r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("transparent pixel = (%d,%d,%d), want white", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestNormalizeResizeAndAspect(t *testing.T) {
+	tests := []struct {
+		name  string
+		w, h  int
+		opts  Options
+		wantW int
+		wantH int
+	}{
+		{"keeps small image", 400, 300, Options{}, 400, 300},
+		{"shrinks long side", 3000, 1000, Options{MaxDimension: 1200}, 1200, 400},
+		{"crops to portrait", 400, 300, Options{Aspect: AspectPortrait}, 225, 300},
+		{"crops to square", 300, 400, Options{Aspect: AspectSquare}, 300, 300},
+		{"pads to portrait", 400, 300, Options{Aspect: AspectPortrait, Fit: FitPad}, 400, 534},
+		{"pads to landscape", 300, 300, Options{Aspect: AspectLandscape, Fit: FitPad}, 400, 300},
+		{"crops then shrinks", 2000, 2000, Options{Aspect: AspectPortrait, MaxDimension: 1000}, 750, 1000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, test.w, test.h))); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := Normalize(buf.Bytes(), test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if size := decodeJPEG(t, out).Bounds().Size(); size != image.Pt(test.wantW, test.wantH) {
+				t.Errorf("size = %v, want %dx%d", size, test.wantW, test.wantH)
+			}
+		})
+	}
+}
+
+func TestNormalizePadKeepsContentCentered(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			src.Set(x, y, color.Black)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Normalize(buf.Bytes(), Options{Aspect: AspectPortrait, Fit: FitPad})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := decodeJPEG(t, out)
+	// 200x200 填充为 200x267，上下各留白约 33 像素
+	if r, _, _, _ := img.At(100, 5).RGBA(); r>>8 < 250 {
+		t.Errorf("top padding = %d, want white", r>>8)
+	}
+	if r, _, _, _ := img.At(100, 133).RGBA(); r>>8 > 5 {
+		t.Errorf("center = %d, want black", r>>8)
+	}
+}
+
+func TestNormalizeRejectsUnsupportedImage(t *testing.T) {
+	_, err := Normalize([]byte("definitely not an image"), Options{})
+	if !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("err = %v, want ErrUnsupportedImage", err)
+	}
+}
+
+func TestNormalizeFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "in.png")
+	dst := filepath.Join(dir, "out.jpg")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 120, 160))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NormalizeFile(src, dst, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := decodeJPEG(t, data).Bounds().Size(); size != image.Pt(120, 160) {
+		t.Errorf("size = %v, want 120x160", size)
+	}
+}
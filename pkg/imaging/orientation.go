@@ -0,0 +1,188 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientationTag EXIF 中方向标签的编号
+const exifOrientationTag = 0x0112
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader   = []byte("Exif\x00\x00")
+)
+
+// readOrientation 读取 JPEG、PNG、WebP 中 EXIF 记录的方向（1-8），没有记录或无法解析时返回 1
+func readOrientation(data []byte) int {
+	var tiff []byte
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		tiff = jpegExif(data)
+	case bytes.HasPrefix(data, pngSignature):
+		tiff = pngExif(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		tiff = webpExif(data)
+	}
+	return tiffOrientation(tiff)
+}
+
+// jpegExif 返回 APP1 段中的 TIFF 数据
+func jpegExif(data []byte) []byte {
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		// 图像数据开始后不再有元数据段
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0xFF {
+			i++
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return segment[len(exifHeader):]
+		}
+		i = end
+	}
+	return nil
+}
+
+// pngExif 返回 eXIf 块中的 TIFF 数据
+func pngExif(data []byte) []byte {
+	for i := len(pngSignature); i+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		kind := string(data[i+4 : i+8])
+		start := i + 8
+		end := start + length
+		if end+4 > len(data) {
+			return nil
+		}
+		if kind == "eXIf" {
+			return data[start:end]
+		}
+		if kind == "IEND" {
+			return nil
+		}
+		i = end + 4 // 跳过 CRC
+	}
+	return nil
+}
+
+// webpExif 返回 EXIF 块中的 TIFF 数据，部分编码器会在前面保留 "Exif\0\0"
+func webpExif(data []byte) []byte {
+	for i := 12; i+8 <= len(data); {
+		kind := string(data[i : i+4])
+		length := int(binary.LittleEndian.Uint32(data[i+4:]))
+		start := i + 8
+		end := start + length
+		if end > len(data) {
+			return nil
+		}
+		if kind == "EXIF" {
+			return bytes.TrimPrefix(data[start:end], exifHeader)
+		}
+		i = end + length%2 // 块按偶数字节对齐
+	}
+	return nil
+}
+
+// tiffOrientation 从 TIFF 数据的第一个 IFD 中读取方向标签
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// 方向标签类型为 SHORT，值直接存放在条目中
+		if order.Uint16(tiff[entry+2:]) != 3 {
+			return 1
+		}
+		if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+			return v
+		}
+		return 1
+	}
+	return 1
+}
+
+// orient 按 EXIF 方向旋转或翻转图片，使其以正确方向显示
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	// 5-8 需要交换宽高
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	// source 返回目标像素 (x, y) 对应的原图坐标
+	var source func(x, y int) (int, int)
+	switch orientation {
+	case 2: // 水平翻转
+		source = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3: // 旋转 180°
+		source = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4: // 垂直翻转
+		source = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5: // 沿主对角线翻转
+		source = func(x, y int) (int, int) { return y, x }
+	case 6: // 顺时针旋转 90°
+		source = func(x, y int) (int, int) { return y, h - 1 - x }
+	case 7: // 沿副对角线翻转
+		source = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case 8: // 逆时针旋转 90°
+		source = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := source(x, y)
+			si := img.PixOffset(img.Bounds().Min.X+sx, img.Bounds().Min.Y+sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+	return dst
+}
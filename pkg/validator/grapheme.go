@@ -0,0 +1,62 @@
+package validator
+
+import "unicode"
+
+const (
+	zeroWidthJoiner = '\u200d'
+	combiningKeycap = '\u20e3'
+)
+
+// CountGraphemes 按用户感知字符计数，一个 emoji（含肤色、ZWJ 组合、国旗、键帽）只计为 1 个字。
+// 这是对 Unicode 字素簇规则的简化实现，覆盖标题与正文中常见的 emoji 与组合字符。
+func CountGraphemes(s string) int {
+	count := 0
+	prevRegional := false
+	joinNext := false
+
+	for _, r := range s {
+		switch {
+		case joinNext:
+			// ZWJ 之后的字符与前一个字符组成同一个字素
+			joinNext = false
+		case r == zeroWidthJoiner:
+			joinNext = true
+		case isExtend(r):
+			// 组合记号、变体选择符、肤色修饰符等附着在前一个字符上
+		case isRegionalIndicator(r):
+			// 两个区域指示符组成一面国旗
+			if prevRegional {
+				prevRegional = false
+				continue
+			}
+			prevRegional = true
+			count++
+			continue
+		default:
+			count++
+		}
+		prevRegional = false
+	}
+
+	return count
+}
+
+func isExtend(r rune) bool {
+	switch {
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r):
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // 变体选择符
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // 肤色修饰符
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // 标签字符（地区旗帜）
+		return true
+	case r == combiningKeycap:
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
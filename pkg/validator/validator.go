@@ -0,0 +1,263 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/h2non/filetype"
+	_ "golang.org/x/image/webp"
+)
+
+// 小红书发布限制
+const (
+	MaxTitleLength   = 20               // 标题最大字数
+	MaxContentLength = 1000             // 正文最大字数
+	MaxImages        = 18               // 单篇笔记最多图片数
+	MaxProducts      = 18               // 单篇笔记最多商品数
+	MaxImageBytes    = 32 * 1024 * 1024 // 单张图片最大字节数
+	MinImageSide     = 100              // 图片最短边最小像素
+	MaxImageSide     = 10000            // 图片最长边最大像素
+)
+
+// SupportedImageFormats 发布页支持上传的图片格式
+var SupportedImageFormats = []string{"jpg", "png", "webp"}
+
+// 违规代码
+const (
+	CodeRequired          = "REQUIRED"
+	CodeTooLong           = "TOO_LONG"
+	CodeTooMany           = "TOO_MANY"
+	CodeUnsupportedFormat = "UNSUPPORTED_FORMAT"
+	CodeTooLarge          = "TOO_LARGE"
+	CodeBadDimension      = "BAD_DIMENSION"
+	CodeUnreadable        = "UNREADABLE"
+	CodeOutOfRange        = "OUT_OF_RANGE"
+)
+
+// Violation 单条校验违规
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Limit   int    `json:"limit,omitempty"`
+	Actual  int    `json:"actual,omitempty"`
+}
+
+// ValidationError 包含全部违规项的校验错误
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", v.Field, v.Message))
+	}
+	return "内容校验失败: " + strings.Join(msgs, "; ")
+}
+
+// Content 待校验的发布内容
+type Content struct {
+	Title      string
+	Content    string
+	Images     []string // 图片 URL 或本地路径
+	CoverIndex int      // 封面图片下标
+	Products   []string
+
+	// DeferImageFiles 图片会在上传前重新处理，本地文件不在此检查，处理后再调用 ValidateImageFiles
+	DeferImageFiles bool
+}
+
+// Validate 校验发布内容，返回包含全部违规项的 *ValidationError，无违规时返回 nil。
+// 本地图片文件会同时检查格式、大小与尺寸；URL 图片只计入数量，需下载后再调用 ValidateImageFiles。
+func Validate(c Content) error {
+	var violations []Violation
+
+	violations = append(violations, checkText("title", c.Title, MaxTitleLength, "标题")...)
+	violations = append(violations, checkText("content", c.Content, MaxContentLength, "正文")...)
+
+	if n := len(c.Images); n > MaxImages {
+		violations = append(violations, Violation{
+			Field:   "images",
+			Code:    CodeTooMany,
+			Message: fmt.Sprintf("图片最多%d张，当前%d张", MaxImages, n),
+			Limit:   MaxImages,
+			Actual:  n,
+		})
+	}
+
+	if c.CoverIndex < 0 || (c.CoverIndex > 0 && c.CoverIndex >= len(c.Images)) {
+		violations = append(violations, Violation{
+			Field:   "cover_index",
+			Code:    CodeOutOfRange,
+			Message: fmt.Sprintf("封面下标%d超出图片范围（共%d张）", c.CoverIndex, len(c.Images)),
+			Limit:   len(c.Images) - 1,
+			Actual:  c.CoverIndex,
+		})
+	}
+
+	if n := countNonEmpty(c.Products); n > MaxProducts {
+		violations = append(violations, Violation{
+			Field:   "products",
+			Code:    CodeTooMany,
+			Message: fmt.Sprintf("商品最多%d个，当前%d个", MaxProducts, n),
+			Limit:   MaxProducts,
+			Actual:  n,
+		})
+	}
+
+	for i, img := range c.Images {
+		if c.DeferImageFiles || isURL(img) {
+			continue
+		}
+		if info, err := os.Stat(img); err != nil || info.IsDir() {
+			continue
+		}
+		violations = append(violations, checkImageFile(i, img)...)
+	}
+
+	return newError(violations)
+}
+
+// ValidateUpdate 校验笔记修改内容，空字段表示保持不变因此跳过检查
+func ValidateUpdate(c Content) error {
+	var violations []Violation
+
+	if c.Title != "" {
+		violations = append(violations, checkText("title", c.Title, MaxTitleLength, "标题")...)
+	}
+	if c.Content != "" {
+		violations = append(violations, checkText("content", c.Content, MaxContentLength, "正文")...)
+	}
+
+	if n := len(c.Images); n > MaxImages {
+		violations = append(violations, Violation{
+			Field:   "images",
+			Code:    CodeTooMany,
+			Message: fmt.Sprintf("图片最多%d张，当前%d张", MaxImages, n),
+			Limit:   MaxImages,
+			Actual:  n,
+		})
+	}
+
+	return newError(violations)
+}
+
+// ValidateImageFiles 校验本地图片文件的格式、大小与尺寸
+func ValidateImageFiles(paths []string) error {
+	var violations []Violation
+	for i, path := range paths {
+		violations = append(violations, checkImageFile(i, path)...)
+	}
+	return newError(violations)
+}
+
+func newError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+func checkText(field, text string, limit int, name string) []Violation {
+	if strings.TrimSpace(text) == "" {
+		return []Violation{{
+			Field:   field,
+			Code:    CodeRequired,
+			Message: name + "不能为空",
+		}}
+	}
+
+	if n := CountGraphemes(text); n > limit {
+		return []Violation{{
+			Field:   field,
+			Code:    CodeTooLong,
+			Message: fmt.Sprintf("%s最多%d字，当前%d字", name, limit, n),
+			Limit:   limit,
+			Actual:  n,
+		}}
+	}
+
+	return nil
+}
+
+func checkImageFile(index int, path string) []Violation {
+	field := fmt.Sprintf("images[%d]", index)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Violation{{Field: field, Code: CodeUnreadable, Message: "无法读取图片: " + err.Error()}}
+	}
+
+	var violations []Violation
+
+	if len(data) > MaxImageBytes {
+		violations = append(violations, Violation{
+			Field:   field,
+			Code:    CodeTooLarge,
+			Message: fmt.Sprintf("图片大小不能超过%dMB", MaxImageBytes/1024/1024),
+			Limit:   MaxImageBytes,
+			Actual:  len(data),
+		})
+	}
+
+	kind, _ := filetype.Match(data)
+	if !isSupportedFormat(kind.Extension) {
+		violations = append(violations, Violation{
+			Field:   field,
+			Code:    CodeUnsupportedFormat,
+			Message: fmt.Sprintf("不支持的图片格式 %q，仅支持 %s", kind.Extension, strings.Join(SupportedImageFormats, "、")),
+		})
+		return violations
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return append(violations, Violation{Field: field, Code: CodeUnreadable, Message: "无法解析图片: " + err.Error()})
+	}
+
+	shortSide, longSide := cfg.Width, cfg.Height
+	if shortSide > longSide {
+		shortSide, longSide = longSide, shortSide
+	}
+
+	if shortSide < MinImageSide || longSide > MaxImageSide {
+		violations = append(violations, Violation{
+			Field:   field,
+			Code:    CodeBadDimension,
+			Message: fmt.Sprintf("图片尺寸 %dx%d 不合法，短边需不小于%dpx，长边需不大于%dpx", cfg.Width, cfg.Height, MinImageSide, MaxImageSide),
+		})
+	}
+
+	return violations
+}
+
+func isSupportedFormat(ext string) bool {
+	for _, f := range SupportedImageFormats {
+		if ext == f {
+			return true
+		}
+	}
+	return false
+}
+
+func isURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func countNonEmpty(items []string) int {
+	n := 0
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			n++
+		}
+	}
+	return n
+}
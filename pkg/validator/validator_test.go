@@ -0,0 +1,149 @@
+package validator
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountGraphemes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"小红书", 3},
+		{"👍", 1},
+		{"👍🏻", 1},      // 肤色修饰符
+		{"👨‍👩‍👧‍👦", 1}, // ZWJ 家庭组合
+		{"🇨🇳🇺🇸", 2},    // 两面国旗
+		{"1️⃣", 1},     // 键帽
+		{"❤️好物", 3},    // 变体选择符
+		{"e\u0301", 1}, // e + 组合重音
+	}
+
+	for _, test := range tests {
+		if got := CountGraphemes(test.input); got != test.expected {
+			t.Errorf("CountGraphemes(%q) = %d, expected %d", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	err := Validate(Content{
+		Title:    strings.Repeat("标", 20),
+		Content:  strings.Repeat("😀", 1000),
+		Images:   []string{"https://example.com/1.jpg"},
+		Products: []string{"莓茶"},
+	})
+	if err != nil {
+		t.Fatalf("expected no violations, got %v", err)
+	}
+
+	products := make([]string, MaxProducts+1)
+	for i := range products {
+		products[i] = "商品"
+	}
+
+	err = Validate(Content{
+		Title:      strings.Repeat("标", 21),
+		Content:    "",
+		Images:     make([]string, MaxImages+1),
+		CoverIndex: MaxImages + 1,
+		Products:   products,
+	})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	codes := map[string]string{}
+	for _, v := range verr.Violations {
+		codes[v.Field] = v.Code
+	}
+
+	expected := map[string]string{
+		"title":       CodeTooLong,
+		"content":     CodeRequired,
+		"images":      CodeTooMany,
+		"products":    CodeTooMany,
+		"cover_index": CodeOutOfRange,
+	}
+	for field, code := range expected {
+		if codes[field] != code {
+			t.Errorf("violation for %s = %q, expected %q", field, codes[field], code)
+		}
+	}
+}
+
+func TestValidateImageFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	good := writePNG(t, dir, "good.png", 300, 400)
+	small := writePNG(t, dir, "small.png", 50, 400)
+
+	text := filepath.Join(dir, "note.png")
+	if err := os.WriteFile(text, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateImageFiles([]string{good}); err != nil {
+		t.Fatalf("expected no violations, got %v", err)
+	}
+
+	err := ValidateImageFiles([]string{good, small, text})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	if len(verr.Violations) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", verr.Violations)
+	}
+
+	if v := verr.Violations[0]; v.Field != "images[1]" || v.Code != CodeBadDimension {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+
+	if v := verr.Violations[1]; v.Field != "images[2]" || v.Code != CodeUnsupportedFormat {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+}
+
+func TestValidateDeferImageFiles(t *testing.T) {
+	small := writePNG(t, t.TempDir(), "small.png", 50, 400)
+
+	content := Content{Title: "标题", Content: "正文", Images: []string{small}}
+	if err := Validate(content); err == nil {
+		t.Fatal("expected violation for small image")
+	}
+
+	content.DeferImageFiles = true
+	if err := Validate(content); err != nil {
+		t.Errorf("expected image files to be skipped, got %v", err)
+	}
+}
+
+func writePNG(t *testing.T, dir, name string, width, height int) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
@@ -56,8 +56,23 @@ func setupRoutes(appServer *AppServer) *gin.Engine {
         api.POST("/login", appServer.loginHandler)
         api.GET("/sessions", appServer.listSessionsHandler)
         api.POST("/publish", appServer.publishHandler)
+        api.GET("/notes/status", appServer.getNoteStatusHandler)
+        api.PUT("/notes/:note_id", appServer.editNoteHandler)
+        api.DELETE("/notes/:note_id", appServer.deleteNoteHandler)
+        api.GET("/products", appServer.listProductsHandler)
         api.GET("/feeds/list", appServer.listFeedsHandler)
+        api.GET("/feeds/channels", appServer.listChannelsHandler)
         api.GET("/feeds/search", appServer.searchFeedsHandler)
+        api.GET("/feeds/detail", appServer.getFeedDetailHandler)
+        api.POST("/feeds/comment", appServer.postCommentHandler)
+        api.POST("/feeds/comment/reply", appServer.replyCommentHandler)
+        api.POST("/feeds/like", appServer.likeFeedHandler)
+        api.POST("/feeds/collect", appServer.collectFeedHandler)
+        api.POST("/feeds/media", appServer.downloadFeedMediaHandler)
+        api.GET("/cache", appServer.imageCacheStatsHandler)
+        api.DELETE("/cache", appServer.purgeImageCacheHandler)
+        api.GET("/users/profile", appServer.userProfileHandler)
+        api.POST("/users/follow", appServer.followUserHandler)
         
         // AI生成路由
         api.POST("/ai/generate", appServer.aiGenerateHandler)
@@ -2,25 +2,53 @@ package main
 
 import (
     "context"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
     "os"
+    "path/filepath"
     "strings"
+    "time"
 
+    "github.com/go-rod/rod"
     "github.com/sirupsen/logrus"
     "github.com/xpzouying/xiaohongshu-mcp/browser"
     "github.com/xpzouying/xiaohongshu-mcp/configs"
     "github.com/xpzouying/xiaohongshu-mcp/pkg/ai"
     "github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+    "github.com/xpzouying/xiaohongshu-mcp/pkg/idempotency"
+    "github.com/xpzouying/xiaohongshu-mcp/pkg/imaging"
+    "github.com/xpzouying/xiaohongshu-mcp/pkg/validator"
     "github.com/xpzouying/xiaohongshu-mcp/xiaohongshu"
 )
 
 // XiaohongshuService 小红书业务服务
-type XiaohongshuService struct{}
+type XiaohongshuService struct {
+    idempotency *idempotency.Store
+
+    // searchSessions 保留搜索页面，翻页时继续滚动而不是重新搜索
+    searchSessions *xiaohongshu.SearchSessions
+}
 
 // NewXiaohongshuService 创建小红书服务实例
 func NewXiaohongshuService() *XiaohongshuService {
-    return &XiaohongshuService{}
+    store, err := idempotency.NewStore(configs.GetIdempotencyStorePath(), configs.GetIdempotencyWindow())
+    if err != nil {
+        logrus.Warnf("加载幂等记录失败，将仅在内存中保存: %v", err)
+        store, _ = idempotency.NewStore("", configs.GetIdempotencyWindow())
+    }
+
+    return &XiaohongshuService{
+        idempotency: store,
+        searchSessions: xiaohongshu.NewSearchSessions(func() *rod.Page {
+            return browser.NewBrowser(browser.GetManager().IsHeadless()).NewPage()
+        }),
+    }
 }
 
+// publishVerifyTimeout 发布后等待笔记出现在笔记管理列表的最长时间
+const publishVerifyTimeout = 30 * time.Second
+
 // PublishRequest 发布请求
 type PublishRequest struct {
     Title    string   `json:"title" binding:"required"`
@@ -28,6 +56,65 @@ type PublishRequest struct {
     Images   []string `json:"images"`
     Tags     []string `json:"tags,omitempty"`
     Products []string `json:"products,omitempty"`
+
+    ProductIDs   []string `json:"product_ids,omitempty"`   // 按商品ID添加商品
+    ProductMatch string   `json:"product_match,omitempty"` // 商品名称匹配方式：contains（默认）、exact
+
+    // 商品添加失败时的处理策略：fail（默认）、skip-missing、skip-and-warn
+    ProductPolicy string `json:"product_policy,omitempty"`
+
+    CoverIndex int      `json:"cover_index,omitempty"` // 封面图片在 images 中的下标，默认第一张
+    Visibility string   `json:"visibility,omitempty"`  // public、private、friends，默认 public
+    Location   string   `json:"location,omitempty"`    // 地点搜索关键词
+    Mentions   []string `json:"mentions,omitempty"`    // 需要@的用户昵称
+    IsOriginal bool     `json:"is_original,omitempty"` // 是否声明原创
+
+    DryRun bool `json:"dry_run,omitempty"` // 只填写表单不提交
+
+    // IdempotencyKey 客户端提供的幂等键，有效期内重复请求直接返回首次发布结果
+    IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+    ImageOptions *ImageOptions `json:"image_options,omitempty"` // 上传前的图片处理参数，不填时使用默认值
+}
+
+// ImageOptions 上传前的图片处理参数。
+// 图片默认会按 EXIF 方向摆正、去除元数据、转换为 JPEG，并缩小到最长边不超过 2560 像素。
+type ImageOptions struct {
+    Disabled     bool   `json:"disabled,omitempty"`      // 不处理，原样上传
+    MaxDimension int    `json:"max_dimension,omitempty"` // 最长边像素上限，100-10000
+    Aspect       string `json:"aspect,omitempty"`        // 目标宽高比：original（默认）、3:4、1:1、4:3
+    Fit          string `json:"fit,omitempty"`           // 调整宽高比的方式：crop 居中裁剪（默认）、pad 白色留白
+    Quality      int    `json:"quality,omitempty"`       // JPEG 质量 1-100，默认 90
+}
+
+// parse 转换为图片处理参数，o 为 nil 时返回默认参数
+func (o *ImageOptions) parse() (imaging.Options, error) {
+    if o == nil {
+        return imaging.Options{}, nil
+    }
+
+    aspect, err := imaging.ParseAspect(o.Aspect)
+    if err != nil {
+        return imaging.Options{}, err
+    }
+
+    fit, err := imaging.ParseFit(o.Fit)
+    if err != nil {
+        return imaging.Options{}, err
+    }
+
+    opts := imaging.Options{
+        MaxDimension: o.MaxDimension,
+        Aspect:       aspect,
+        Fit:          fit,
+        Quality:      o.Quality,
+    }
+    return opts, opts.Validate()
+}
+
+// normalizeImagesEnabled 是否在上传前处理图片
+func (o *ImageOptions) normalizeImagesEnabled() bool {
+    return o == nil || !o.Disabled
 }
 
 // LoginStatusResponse 登录状态响应
@@ -43,12 +130,58 @@ type PublishResponse struct {
     Images  int    `json:"images"`
     Status  string `json:"status"`
     PostID  string `json:"post_id,omitempty"`
+
+    DryRun     bool                      `json:"dry_run,omitempty"`
+    Form       *xiaohongshu.FormSnapshot `json:"form,omitempty"`       // dry-run 时页面上实际填写的内容
+    Screenshot string                    `json:"screenshot,omitempty"` // dry-run 截图，base64 编码的 PNG
+
+    ImageUploads []xiaohongshu.ImageUpload     `json:"image_uploads,omitempty"` // 每张图片的上传耗时
+    Products     []xiaohongshu.SelectedProduct `json:"products,omitempty"`      // 实际选中的商品
+
+    SkippedProducts []xiaohongshu.SkippedProduct `json:"skipped_products,omitempty"` // 按失败处理策略跳过的商品
+
+    IdempotencyKey string `json:"idempotency_key,omitempty"`
+    Replayed       bool   `json:"replayed,omitempty"` // 是否为幂等键命中后返回的历史结果
+
+    Note *xiaohongshu.ManagedNote `json:"note,omitempty"` // 发布后在笔记管理列表中确认到的笔记及审核状态
 }
 
 // FeedsListResponse Feeds列表响应
 type FeedsListResponse struct {
     Feeds []xiaohongshu.Feed `json:"feeds"`
     Count int                `json:"count"`
+
+    NextCursor string `json:"next_cursor,omitempty"` // 搜索结果下一页游标
+    HasMore    bool   `json:"has_more,omitempty"`
+}
+
+// ListFeedsRequest 首页笔记列表请求
+type ListFeedsRequest struct {
+    Channel string `json:"channel,omitempty"` // 频道名称或ID，如 穿搭、homefeed.fashion_v3，默认推荐频道
+    Limit   int    `json:"limit,omitempty"`   // 返回笔记数量，超过首屏数量时滚动加载
+}
+
+// ChannelListResponse 首页频道列表响应
+type ChannelListResponse struct {
+    Channels []xiaohongshu.Channel `json:"channels"`
+    Count    int                   `json:"count"`
+}
+
+// SearchFeedsRequest 搜索请求
+type SearchFeedsRequest struct {
+    Keyword string `json:"keyword"`
+    Limit   int    `json:"limit,omitempty"`  // 返回结果数量，默认20，最多200
+    Cursor  string `json:"cursor,omitempty"` // 上一页返回的 next_cursor
+
+    Sort        string `json:"sort,omitempty"`         // 排序：general、latest、most_liked、most_commented、most_collected
+    NoteType    string `json:"note_type,omitempty"`    // 笔记类型：all、video、image
+    PublishTime string `json:"publish_time,omitempty"` // 发布时间：all、day、week、half_year
+}
+
+// ProductListResponse 商品列表响应
+type ProductListResponse struct {
+    Products []xiaohongshu.Product `json:"products"`
+    Count    int                   `json:"count"`
 }
 
 // CheckLoginStatus 检查登录状态
@@ -74,10 +207,111 @@ func (s *XiaohongshuService) CheckLoginStatus(ctx context.Context) (*LoginStatus
     return response, nil
 }
 
-// PublishContent 发布内容
+// PublishContent 发布内容，提供幂等键时同一请求在有效期内只会发布一次
 func (s *XiaohongshuService) PublishContent(ctx context.Context, req *PublishRequest) (*PublishResponse, error) {
+    key := strings.TrimSpace(req.IdempotencyKey)
+    if key == "" || req.DryRun {
+        return s.publish(ctx, req, nil)
+    }
+
+    record, started, err := s.idempotency.Begin(key, idempotency.Fingerprint(req))
+    if err != nil {
+        return nil, err
+    }
+
+    if !started {
+        if record.Status == idempotency.StatusSubmitted {
+            logrus.Infof("幂等键 %s 已于 %s 点击发布但结果未知，不再重复发布", key, record.UpdatedAt.Format(time.RFC3339))
+            return &PublishResponse{
+                Title:          req.Title,
+                Content:        req.Content,
+                Images:         len(req.Images),
+                Status:         "已点击发布但未确认结果，请通过笔记状态查询确认",
+                PostID:         record.NoteID,
+                IdempotencyKey: key,
+                Replayed:       true,
+            }, nil
+        }
+
+        var cached PublishResponse
+        if err := json.Unmarshal(record.Result, &cached); err != nil {
+            return nil, fmt.Errorf("读取幂等结果失败: %w", err)
+        }
+        if cached.PostID == "" {
+            cached.PostID = record.NoteID
+        }
+        cached.Replayed = true
+        logrus.Infof("幂等键 %s 已于 %s 发布，直接返回历史结果", key, record.CreatedAt.Format(time.RFC3339))
+        return &cached, nil
+    }
+
+    submitted := false
+    response, err := s.publish(ctx, req, func() error {
+        submitted = true
+        if err := s.idempotency.MarkSubmitted(key); err != nil {
+            logrus.Warnf("保存幂等键提交状态失败: %v", err)
+        }
+        return nil
+    })
+    if err != nil {
+        // 点击发布后的失败（等待结果超时、请求被取消等）无法确认笔记是否已发布，
+        // 保留 submitted 记录，客户端重试时不会重复发布
+        if submitted {
+            logrus.Warnf("幂等键 %s 已点击发布但未确认结果，保留记录: %v", key, err)
+            return nil, err
+        }
+        if releaseErr := s.idempotency.Release(key); releaseErr != nil {
+            logrus.Warnf("释放幂等键失败: %v", releaseErr)
+        }
+        return nil, err
+    }
+
+    response.IdempotencyKey = key
+    if err := s.idempotency.Complete(key, response, response.PostID); err != nil {
+        logrus.Warnf("保存幂等结果失败: %v", err)
+    }
+
+    return response, nil
+}
+
+// publish 执行一次发布流程，beforeSubmit 在点击发布按钮前调用
+func (s *XiaohongshuService) publish(ctx context.Context, req *PublishRequest, beforeSubmit func() error) (*PublishResponse, error) {
     logrus.Infof("开始处理发布请求: 标题=%s, 图片数量=%d, 标签数量=%d, 商品数量=%d", req.Title, len(req.Images), len(req.Tags), len(req.Products))
 
+    visibility, err := xiaohongshu.ParseVisibility(req.Visibility)
+    if err != nil {
+        return nil, err
+    }
+
+    productMatch, err := xiaohongshu.ParseProductMatchMode(req.ProductMatch)
+    if err != nil {
+        return nil, err
+    }
+
+    productPolicy, err := xiaohongshu.ParseProductFailurePolicy(req.ProductPolicy)
+    if err != nil {
+        return nil, err
+    }
+
+    imageOptions, err := req.ImageOptions.parse()
+    if err != nil {
+        return nil, err
+    }
+    normalize := req.ImageOptions.normalizeImagesEnabled()
+
+    // 在打开浏览器之前校验内容，一次性返回全部违规项
+    if err := validator.Validate(validator.Content{
+        Title:      req.Title,
+        Content:    req.Content,
+        Images:     req.Images,
+        CoverIndex: req.CoverIndex,
+        Products:   append(append([]string{}, req.Products...), req.ProductIDs...),
+
+        DeferImageFiles: normalize,
+    }); err != nil {
+        return nil, err
+    }
+
     var imagePaths []string
 
     if len(req.Images) == 0 {
@@ -113,11 +347,23 @@ func (s *XiaohongshuService) PublishContent(ctx context.Context, req *PublishReq
         if len(virtualImagePaths) == len(req.Images) {
             logrus.Warnf("检测到虚拟图片路径: %v，将发布纯文本内容", virtualImagePaths)
         } else {
-            processed, err := s.processImages(req.Images)
+            processed, release, err := s.processImages(ctx, req.Images)
             if err != nil {
                 logrus.Errorf("图片处理失败: %v", err)
                 return nil, err
             }
+            defer release()
+            if normalize {
+                normalized, cleanup, err := normalizeImages(req.Images, processed, imageOptions)
+                if err != nil {
+                    return nil, err
+                }
+                defer cleanup()
+                processed = normalized
+            }
+            if err := validator.ValidateImageFiles(processed); err != nil {
+                return nil, err
+            }
             imagePaths = processed
             logrus.Infof("图片处理完成，有效图片数量: %d", len(imagePaths))
         }
@@ -130,10 +376,38 @@ func (s *XiaohongshuService) PublishContent(ctx context.Context, req *PublishReq
         Tags:       req.Tags,
         Products:   req.Products,
         ImagePaths: imagePaths,
+
+        ProductIDs:    req.ProductIDs,
+        ProductMatch:  productMatch,
+        ProductPolicy: productPolicy,
+
+        CoverIndex: req.CoverIndex,
+        Visibility: visibility,
+        Location:   req.Location,
+        Mentions:   req.Mentions,
+        IsOriginal: req.IsOriginal,
+        DryRun:     req.DryRun,
+    }
+
+    // 点击发布前记录笔记管理列表中已有的笔记，发布后只匹配新出现的笔记，避免把同标题的旧笔记当作本次发布
+    var knownNotes map[string]bool
+    content.BeforeSubmit = func() error {
+        known, err := s.snapshotNoteIDs(ctx)
+        if err != nil {
+            logrus.Warnf("记录已有笔记失败，发布后将无法确认笔记状态: %v", err)
+        } else {
+            knownNotes = known
+        }
+
+        if beforeSubmit != nil {
+            return beforeSubmit()
+        }
+        return nil
     }
 
     // 执行发布
-    if err := s.publishContent(ctx, content); err != nil {
+    result, err := s.publishContent(ctx, content)
+    if err != nil {
         logrus.Errorf("发布内容执行失败: %v", err)
         return nil, err
     }
@@ -142,26 +416,106 @@ func (s *XiaohongshuService) PublishContent(ctx context.Context, req *PublishReq
     if len(imagePaths) == 0 {
         status = "发布完成（纯文本）"
     }
+    if result.Unconfirmed {
+        status = "已提交发布，未确认发布结果"
+    }
+    if result.DryRun {
+        status = "试运行完成，未提交发布"
+    }
 
     response := &PublishResponse{
         Title:   req.Title,
         Content: req.Content,
         Images:  len(imagePaths),
         Status:  status,
+        DryRun:  result.DryRun,
+        Form:    result.Form,
+
+        ImageUploads: result.ImageUploads,
+        Products:     result.Products,
+
+        SkippedProducts: result.Skipped,
+    }
+    if len(result.Screenshot) > 0 {
+        response.Screenshot = base64.StdEncoding.EncodeToString(result.Screenshot)
     }
 
-    logrus.Infof("发布内容处理完成: %+v", response)
+    // 点击发布不代表笔记已被接收，到笔记管理列表确认审核状态
+    if !result.DryRun {
+        if knownNotes == nil {
+            response.Status += "（未能确认笔记状态）"
+        } else if note, err := s.waitForPublishedNote(ctx, req.Title, knownNotes); err != nil {
+            logrus.Warnf("发布后确认笔记状态失败: %v", err)
+            response.Status += "（未能确认笔记状态）"
+        } else {
+            response.PostID = note.NoteID
+            response.Note = note
+            switch note.Status {
+            case xiaohongshu.NoteStatusReviewing:
+                response.Status += "，笔记审核中"
+            case xiaohongshu.NoteStatusRejected:
+                response.Status += "，笔记审核未通过: " + note.Reason
+            case xiaohongshu.NoteStatusUnknown:
+                response.Status += "，未能识别笔记状态"
+            }
+        }
+    }
+
+    logrus.Infof("发布内容处理完成: 标题=%s, 图片数量=%d, 状态=%s", response.Title, response.Images, response.Status)
     return response, nil
 }
 
-// processImages 处理图片列表，支持URL下载和本地路径
-func (s *XiaohongshuService) processImages(images []string) ([]string, error) {
+// processImages 处理图片列表，支持URL下载和本地路径。
+// 上传完成前下载的图片不会被缓存清理删除，使用完后调用返回的 release 释放
+func (s *XiaohongshuService) processImages(ctx context.Context, images []string) ([]string, func(), error) {
     processor := downloader.NewImageProcessor()
-    return processor.ProcessImages(images)
+    paths, err := processor.ProcessImages(ctx, images)
+    if err != nil {
+        return nil, nil, err
+    }
+    return paths, func() { processor.Release(paths) }, nil
+}
+
+// normalizeImages 按 opts 处理图片并写入临时目录，返回处理后的路径和删除临时目录的函数。
+// paths[i] 为请求中 images[i] 对应的本地文件，无法解码的图片按请求中的序号作为校验违规返回。
+// 临时目录不放在图片缓存目录下，避免被缓存清理或统计。
+func normalizeImages(images, paths []string, opts imaging.Options) ([]string, func(), error) {
+    if len(paths) != len(images) {
+        return nil, nil, fmt.Errorf("图片处理结果数量%d与请求图片数量%d不一致", len(paths), len(images))
+    }
+
+    dir, err := os.MkdirTemp("", "xiaohongshu-normalized-")
+    if err != nil {
+        return nil, nil, fmt.Errorf("创建图片处理目录失败: %w", err)
+    }
+    cleanup := func() { os.RemoveAll(dir) }
+
+    var violations []validator.Violation
+    normalized := make([]string, 0, len(paths))
+    for i, path := range paths {
+        dst := filepath.Join(dir, fmt.Sprintf("image_%02d.jpg", i+1))
+        if err := imaging.NormalizeFile(path, dst, opts); err != nil {
+            violations = append(violations, validator.Violation{
+                Field:   fmt.Sprintf("images[%d]", i),
+                Code:    validator.CodeUnreadable,
+                Message: fmt.Sprintf("图片处理失败（%s）: %v", images[i], err),
+            })
+            continue
+        }
+        normalized = append(normalized, dst)
+    }
+
+    if len(violations) > 0 {
+        cleanup()
+        return nil, nil, &validator.ValidationError{Violations: violations}
+    }
+
+    logrus.Infof("图片预处理完成，共%d张，保存在 %s", len(normalized), dir)
+    return normalized, cleanup, nil
 }
 
 // publishContent 执行内容发布
-func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohongshu.PublishImageContent) error {
+func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohongshu.PublishImageContent) (*xiaohongshu.PublishResult, error) {
     logrus.Infof("开始执行发布，使用环境变量 MCP_HEADLESS: %s", os.Getenv("MCP_HEADLESS"))
 
     // 使用浏览器管理器的当前设置
@@ -176,22 +530,142 @@ func (s *XiaohongshuService) publishContent(ctx context.Context, content xiaohon
     action, err := xiaohongshu.NewPublishImageAction(page)
     if err != nil {
         logrus.Errorf("创建发布action失败: %v", err)
-        return err
+        return nil, err
     }
 
     // 执行发布
     logrus.Info("开始执行发布操作...")
-    if err := action.Publish(ctx, content); err != nil {
+    result, err := action.Publish(ctx, content)
+    if err != nil {
         logrus.Errorf("发布操作失败: %v", err)
-        return err
+        return nil, err
     }
 
     logrus.Info("发布操作完成")
-    return nil
+    return result, nil
+}
+
+// snapshotNoteIDs 在新页面中读取笔记管理列表已有的笔记 ID
+func (s *XiaohongshuService) snapshotNoteIDs(ctx context.Context) (map[string]bool, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewNoteManagerAction(page)
+    return action.SnapshotNoteIDs(ctx)
+}
+
+// waitForPublishedNote 轮询笔记管理列表，确认刚发布的笔记及其审核状态，known 中的笔记不参与匹配
+func (s *XiaohongshuService) waitForPublishedNote(ctx context.Context, title string, known map[string]bool) (*xiaohongshu.ManagedNote, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewNoteManagerAction(page)
+    return action.WaitForNote(ctx, title, known, publishVerifyTimeout)
+}
+
+// GetNoteStatus 查询笔记审核状态，优先按笔记ID匹配，未提供时按标题匹配
+func (s *XiaohongshuService) GetNoteStatus(ctx context.Context, noteID, title string) (*xiaohongshu.ManagedNote, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewNoteManagerAction(page)
+    if noteID != "" {
+        return action.GetNoteStatus(ctx, noteID)
+    }
+
+    return action.FindNote(ctx, func(note *xiaohongshu.ManagedNote) bool {
+        return note.Title == title
+    })
+}
+
+// EditNoteRequest 修改笔记请求，空字段表示保持不变
+type EditNoteRequest struct {
+    Title   string   `json:"title,omitempty"`
+    Content string   `json:"content,omitempty"`
+    Tags    []string `json:"tags,omitempty"`
+    Images  []string `json:"images,omitempty"` // 非空时替换全部图片
+    Confirm bool     `json:"confirm"`          // 必须为 true 才会执行修改
+}
+
+// EditNote 修改已发布的笔记
+func (s *XiaohongshuService) EditNote(ctx context.Context, noteID string, req *EditNoteRequest) error {
+    if err := validator.ValidateUpdate(validator.Content{
+        Title:   req.Title,
+        Content: req.Content,
+        Images:  req.Images,
+    }); err != nil {
+        return err
+    }
+
+    edit := xiaohongshu.NoteEdit{
+        Title:   req.Title,
+        Content: req.Content,
+        Tags:    req.Tags,
+    }
+
+    if len(req.Images) > 0 {
+        processed, release, err := s.processImages(ctx, req.Images)
+        if err != nil {
+            return err
+        }
+        defer release()
+        if err := validator.ValidateImageFiles(processed); err != nil {
+            return err
+        }
+        edit.ImagePaths = processed
+    }
+
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewNoteManagerAction(page)
+    return action.EditNote(ctx, noteID, edit)
+}
+
+// DeleteNote 删除已发布的笔记
+func (s *XiaohongshuService) DeleteNote(ctx context.Context, noteID string) error {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewNoteManagerAction(page)
+    return action.DeleteNote(ctx, noteID)
+}
+
+// ListProducts 获取当前账号可添加到笔记的商品列表
+func (s *XiaohongshuService) ListProducts(ctx context.Context) (*ProductListResponse, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action, err := xiaohongshu.NewProductListAction(page)
+    if err != nil {
+        return nil, err
+    }
+
+    products, err := action.ListProducts(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    return &ProductListResponse{
+        Products: products,
+        Count:    len(products),
+    }, nil
 }
 
 // ListFeeds 获取Feeds列表
-func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse, error) {
+func (s *XiaohongshuService) ListFeeds(ctx context.Context, req *ListFeedsRequest) (*FeedsListResponse, error) {
     // 使用浏览器管理器的当前设置
     manager := browser.GetManager()
     currentHeadless := manager.IsHeadless()
@@ -205,7 +679,10 @@ func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse,
     action := xiaohongshu.NewFeedsListAction(page)
 
     // 获取 Feeds 列表
-    feeds, err := action.GetFeedsList(ctx)
+    feeds, err := action.GetChannelFeeds(ctx, xiaohongshu.FeedsListOptions{
+        Channel: req.Channel,
+        Limit:   req.Limit,
+    })
     if err != nil {
         return nil, err
     }
@@ -218,31 +695,201 @@ func (s *XiaohongshuService) ListFeeds(ctx context.Context) (*FeedsListResponse,
     return response, nil
 }
 
-func (s *XiaohongshuService) SearchFeeds(ctx context.Context, keyword string) (*FeedsListResponse, error) {
-    // 使用浏览器管理器的当前设置
-    manager := browser.GetManager()
-    currentHeadless := manager.IsHeadless()
-    b := browser.NewBrowser(currentHeadless) // 使用当前的无头模式设置
-    // 注意：不再自动关闭浏览器，由浏览器管理器管理生命周期
+// ListChannels 获取首页频道列表
+func (s *XiaohongshuService) ListChannels(ctx context.Context) (*ChannelListResponse, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
 
     page := b.NewPage()
     defer page.Close()
 
-    action := xiaohongshu.NewSearchAction(page)
+    action := xiaohongshu.NewFeedsListAction(page)
 
-    feeds, err := action.Search(ctx, keyword)
+    channels, err := action.ListChannels(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    return &ChannelListResponse{
+        Channels: channels,
+        Count:    len(channels),
+    }, nil
+}
+
+func (s *XiaohongshuService) SearchFeeds(ctx context.Context, req *SearchFeedsRequest) (*FeedsListResponse, error) {
+    filters, err := xiaohongshu.ParseSearchFilters(req.Sort, req.NoteType, req.PublishTime)
+    if err != nil {
+        return nil, err
+    }
+
+    // 搜索页面由会话管理，有下一页时保留到翻页或过期
+    result, err := s.searchSessions.Search(ctx, req.Keyword, xiaohongshu.SearchOptions{
+        Limit:   req.Limit,
+        Cursor:  req.Cursor,
+        Filters: filters,
+    })
     if err != nil {
         return nil, err
     }
 
     response := &FeedsListResponse{
-        Feeds: feeds,
-        Count: len(feeds),
+        Feeds:      result.Feeds,
+        Count:      len(result.Feeds),
+        NextCursor: result.NextCursor,
+        HasMore:    result.HasMore,
     }
 
     return response, nil
 }
 
+// GetFeedDetail 获取笔记详情及第一页评论
+func (s *XiaohongshuService) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (*xiaohongshu.FeedDetailResponse, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewNoteDetailAction(page)
+    return action.GetFeedDetail(ctx, feedID, xsecToken)
+}
+
+// CommentRequest 评论请求，CommentID 非空时回复该评论
+type CommentRequest struct {
+    FeedID    string `json:"feed_id" binding:"required"`
+    XsecToken string `json:"xsec_token" binding:"required"`
+    Content   string `json:"content" binding:"required"`
+    CommentID string `json:"comment_id,omitempty"`
+}
+
+// CommentResponse 评论响应
+type CommentResponse struct {
+    FeedID    string `json:"feed_id"`
+    CommentID string `json:"comment_id,omitempty"` // 被回复的评论ID
+    Success   bool   `json:"success"`
+}
+
+// PostComment 发表评论，CommentID 非空时回复指定评论
+func (s *XiaohongshuService) PostComment(ctx context.Context, req *CommentRequest) (*CommentResponse, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewCommentAction(page)
+
+    var err error
+    if req.CommentID != "" {
+        err = action.ReplyComment(ctx, req.FeedID, req.XsecToken, req.CommentID, req.Content)
+    } else {
+        err = action.PostComment(ctx, req.FeedID, req.XsecToken, req.Content)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    return &CommentResponse{
+        FeedID:    req.FeedID,
+        CommentID: req.CommentID,
+        Success:   true,
+    }, nil
+}
+
+// FeedInteractRequest 点赞、收藏请求
+type FeedInteractRequest struct {
+    FeedID    string `json:"feed_id" binding:"required"`
+    XsecToken string `json:"xsec_token" binding:"required"`
+    Undo      bool   `json:"undo,omitempty"` // 为 true 时取消点赞、取消收藏
+}
+
+// FollowRequest 关注用户请求
+type FollowRequest struct {
+    UserID    string `json:"user_id" binding:"required"`
+    XsecToken string `json:"xsec_token,omitempty"`
+    Undo      bool   `json:"undo,omitempty"` // 为 true 时取消关注
+}
+
+// LikeFeed 点赞或取消点赞笔记
+func (s *XiaohongshuService) LikeFeed(ctx context.Context, req *FeedInteractRequest) (*xiaohongshu.InteractResult, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewInteractAction(page)
+    return action.Like(ctx, req.FeedID, req.XsecToken, !req.Undo)
+}
+
+// CollectFeed 收藏或取消收藏笔记
+func (s *XiaohongshuService) CollectFeed(ctx context.Context, req *FeedInteractRequest) (*xiaohongshu.InteractResult, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewInteractAction(page)
+    return action.Collect(ctx, req.FeedID, req.XsecToken, !req.Undo)
+}
+
+// FollowUser 关注或取消关注用户
+func (s *XiaohongshuService) FollowUser(ctx context.Context, req *FollowRequest) (*xiaohongshu.InteractResult, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewInteractAction(page)
+    return action.Follow(ctx, req.UserID, req.XsecToken, !req.Undo)
+}
+
+// GetUserProfile 获取用户主页信息及笔记列表，limit 为笔记数量上限
+func (s *XiaohongshuService) GetUserProfile(ctx context.Context, userID, xsecToken string, limit int) (*xiaohongshu.UserProfileResponse, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewUserProfileAction(page)
+    return action.GetUserProfile(ctx, userID, xsecToken, limit)
+}
+
+// DownloadMediaRequest 下载笔记媒体请求
+type DownloadMediaRequest struct {
+    FeedID    string `json:"feed_id" binding:"required"`
+    XsecToken string `json:"xsec_token" binding:"required"`
+}
+
+// DownloadFeedMedia 下载笔记的全部图片和视频到媒体目录，并保存笔记元数据
+func (s *XiaohongshuService) DownloadFeedMedia(ctx context.Context, req *DownloadMediaRequest) (*xiaohongshu.MediaArchive, error) {
+    b := browser.NewBrowser(browser.GetManager().IsHeadless())
+
+    page := b.NewPage()
+    defer page.Close()
+
+    action := xiaohongshu.NewMediaDownloadAction(page)
+    return action.DownloadNoteMedia(ctx, req.FeedID, req.XsecToken, configs.GetMediaPath())
+}
+
+// PurgeCacheResponse 清理图片缓存响应
+type PurgeCacheResponse struct {
+    Removed    int   `json:"removed"`     // 删除的图片地址数
+    FreedBytes int64 `json:"freed_bytes"` // 释放的字节数
+}
+
+// GetImageCacheStats 获取图片缓存使用情况
+func (s *XiaohongshuService) GetImageCacheStats() downloader.CacheStats {
+    return downloader.DefaultImageCache().Stats()
+}
+
+// PurgeImageCache 清理超过 olderThan 未使用的缓存图片，olderThan 为 0 时清空缓存
+func (s *XiaohongshuService) PurgeImageCache(olderThan time.Duration) (*PurgeCacheResponse, error) {
+    removed, freed, err := downloader.DefaultImageCache().Purge(olderThan)
+    if err != nil {
+        return nil, err
+    }
+
+    logrus.Infof("已清理图片缓存: %d个地址，释放%d字节", removed, freed)
+    return &PurgeCacheResponse{Removed: removed, FreedBytes: freed}, nil
+}
+
 // AIGenerateRequest AI生成请求
 type AIGenerateRequest struct {
     Topic       string   `json:"topic" binding:"required"`
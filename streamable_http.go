@@ -203,11 +203,88 @@ func (s *AppServer) processToolsList(request *JSONRPCRequest) *JSONRPCResponse {
                     },
                     "products": map[string]interface{}{
                         "type":        "array",
-                        "description": "商品名称列表（可选参数），如 [正宗特级湘西莓茶, 儿时麦芽糖]。系统会从商品列表中模糊匹配并添加商品，最多18个",
+                        "description": "商品名称列表（可选参数），如 [正宗特级湘西莓茶, 儿时麦芽糖]。系统会从商品列表中匹配并添加商品，匹配到多个商品时报错，最多18个",
                         "items": map[string]interface{}{
                             "type": "string",
                         },
                     },
+                    "product_ids": map[string]interface{}{
+                        "type":        "array",
+                        "description": "商品ID列表（可选参数），按商品ID精确添加商品，与 products 合计最多18个",
+                        "items": map[string]interface{}{
+                            "type": "string",
+                        },
+                    },
+                    "product_match": map[string]interface{}{
+                        "type":        "string",
+                        "description": "商品名称匹配方式（可选）：contains 名称包含关键词（默认）、exact 名称与关键词完全相同（区分大小写）",
+                        "enum":        []string{"contains", "exact"},
+                    },
+                    "product_policy": map[string]interface{}{
+                        "type":        "string",
+                        "description": "商品添加失败时的处理策略（可选）：fail 终止发布（默认）、skip-missing 跳过未搜索到的商品、skip-and-warn 跳过所有添加失败的商品。被跳过的商品在结果的 skipped_products 中返回",
+                        "enum":        []string{"fail", "skip-missing", "skip-and-warn"},
+                    },
+                    "cover_index": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "封面图片在 images 中的下标（可选），默认 0 即第一张；图片会按 images 顺序上传",
+                    },
+                    "visibility": map[string]interface{}{
+                        "type":        "string",
+                        "description": "可见范围（可选）：public 公开、private 仅自己可见、friends 仅互关好友可见，默认 public",
+                        "enum":        []string{"public", "private", "friends"},
+                    },
+                    "location": map[string]interface{}{
+                        "type":        "string",
+                        "description": "地点搜索关键词（可选），系统会搜索并选择最匹配的地点",
+                    },
+                    "mentions": map[string]interface{}{
+                        "type":        "array",
+                        "description": "需要@的用户昵称列表（可选），系统会从联想列表中选择匹配的用户",
+                        "items": map[string]interface{}{
+                            "type": "string",
+                        },
+                    },
+                    "is_original": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "是否声明原创（可选）",
+                    },
+                    "dry_run": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "试运行（可选）：执行上传、填写、选品等全部步骤但不点击发布，返回表单截图与实际填写的内容",
+                    },
+                    "idempotency_key": map[string]interface{}{
+                        "type":        "string",
+                        "description": "幂等键（可选），超时重试时传入相同的值，有效期内不会重复发布而是返回首次发布结果",
+                    },
+                    "image_options": map[string]interface{}{
+                        "type":        "object",
+                        "description": "上传前的图片处理参数（可选）。默认按 EXIF 方向摆正、去除元数据、转换为 JPEG，并缩小到最长边不超过 2560 像素",
+                        "properties": map[string]interface{}{
+                            "disabled": map[string]interface{}{
+                                "type":        "boolean",
+                                "description": "不处理图片，原样上传",
+                            },
+                            "max_dimension": map[string]interface{}{
+                                "type":        "integer",
+                                "description": "最长边像素上限，100-10000，只缩小不放大",
+                            },
+                            "aspect": map[string]interface{}{
+                                "type":        "string",
+                                "description": "目标宽高比，默认 original 保持原比例",
+                                "enum":        []string{"original", "3:4", "1:1", "4:3"},
+                            },
+                            "fit": map[string]interface{}{
+                                "type":        "string",
+                                "description": "调整宽高比的方式：crop 居中裁剪（默认）、pad 白色留白保留完整画面",
+                                "enum":        []string{"crop", "pad"},
+                            },
+                            "quality": map[string]interface{}{
+                                "type":        "integer",
+                                "description": "JPEG 质量 1-100，默认 90",
+                            },
+                        },
+                    },
                     "video": map[string]interface{}{
                         "type":        "string",
                         "description": "视频文件路径（发布视频时使用）",
@@ -216,9 +293,110 @@ func (s *AppServer) processToolsList(request *JSONRPCRequest) *JSONRPCResponse {
                 "required": []string{"title", "content"},
             },
         },
+        {
+            "name":        "get_note_status",
+            "description": "查询已发布笔记在创作者中心的审核状态（已发布、审核中、未通过及原因）",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "note_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID（与 title 二选一）",
+                    },
+                    "title": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记标题，未提供笔记ID时按标题匹配最新一篇",
+                    },
+                },
+            },
+        },
+        {
+            "name":        "edit_note",
+            "description": "修改已发布的笔记（标题、正文、话题标签、图片），需要 confirm 为 true",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "note_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "title": map[string]interface{}{
+                        "type":        "string",
+                        "description": "新标题（可选，不填则保持不变）",
+                    },
+                    "content": map[string]interface{}{
+                        "type":        "string",
+                        "description": "新正文（可选，不填则保持不变）",
+                    },
+                    "tags": map[string]interface{}{
+                        "type":        "array",
+                        "description": "追加的话题标签（可选）",
+                        "items": map[string]interface{}{
+                            "type": "string",
+                        },
+                    },
+                    "images": map[string]interface{}{
+                        "type":        "array",
+                        "description": "新图片列表（可选），提供时替换全部原有图片",
+                        "items": map[string]interface{}{
+                            "type": "string",
+                        },
+                    },
+                    "confirm": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "确认执行修改，必须为 true",
+                    },
+                },
+                "required": []string{"note_id", "confirm"},
+            },
+        },
+        {
+            "name":        "delete_note",
+            "description": "删除已发布的笔记，操作不可恢复，需要 confirm 为 true",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "note_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "confirm": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "确认执行删除，必须为 true",
+                    },
+                },
+                "required": []string{"note_id", "confirm"},
+            },
+        },
+        {
+            "name":        "list_products",
+            "description": "获取当前账号可添加到笔记的商品列表，返回商品ID、名称、价格和库存",
+            "inputSchema": map[string]interface{}{
+                "type":       "object",
+                "properties": map[string]interface{}{},
+            },
+        },
         {
             "name":        "list_feeds",
-            "description": "获取用户发布的内容列表",
+            "description": "获取首页推荐的笔记列表，可指定频道（如 穿搭、美食、旅行）和数量",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "channel": map[string]interface{}{
+                        "type":        "string",
+                        "description": "频道名称或ID（可选），可通过 list_channels 获取，默认推荐频道",
+                    },
+                    "limit": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "返回笔记数量（可选），超过首屏数量时滚动加载，默认只返回首屏",
+                        "minimum":     1,
+                    },
+                },
+            },
+        },
+        {
+            "name":        "list_channels",
+            "description": "获取首页频道列表，返回频道ID和名称",
             "inputSchema": map[string]interface{}{
                 "type":       "object",
                 "properties": map[string]interface{}{},
@@ -234,10 +412,208 @@ func (s *AppServer) processToolsList(request *JSONRPCRequest) *JSONRPCResponse {
                         "type":        "string",
                         "description": "搜索关键词",
                     },
+                    "limit": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "返回结果数量（可选），默认20，最多200，超过首屏数量时滚动加载",
+                        "minimum":     1,
+                        "maximum":     200,
+                    },
+                    "cursor": map[string]interface{}{
+                        "type":        "string",
+                        "description": "分页游标（可选），传入上一次结果中的 next_cursor 获取下一页",
+                    },
+                    "sort": map[string]interface{}{
+                        "type":        "string",
+                        "description": "排序方式（可选）：general 综合（默认）、latest 最新、most_liked 最多点赞、most_commented 最多评论、most_collected 最多收藏",
+                        "enum":        []string{"general", "latest", "most_liked", "most_commented", "most_collected"},
+                    },
+                    "note_type": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记类型（可选）：all 不限（默认）、video 视频、image 图文",
+                        "enum":        []string{"all", "video", "image"},
+                    },
+                    "publish_time": map[string]interface{}{
+                        "type":        "string",
+                        "description": "发布时间（可选）：all 不限（默认）、day 一天内、week 一周内、half_year 半年内",
+                        "enum":        []string{"all", "day", "week", "half_year"},
+                    },
                 },
                 "required": []string{"keyword"},
             },
         },
+        {
+            "name":        "get_feed_detail",
+            "description": "获取笔记详情，包括正文、全部图片、标签、发布时间和第一页评论。feed_id 与 xsec_token 来自 list_feeds 或 search_feeds 的结果",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "feed_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID，即 Feed 的 id 字段",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 的 xsecToken 字段",
+                    },
+                },
+                "required": []string{"feed_id", "xsec_token"},
+            },
+        },
+        {
+            "name":        "post_comment",
+            "description": "在笔记下发表评论（前提：用户已登录）",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "feed_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 的 xsecToken 字段",
+                    },
+                    "content": map[string]interface{}{
+                        "type":        "string",
+                        "description": "评论内容",
+                    },
+                },
+                "required": []string{"feed_id", "xsec_token", "content"},
+            },
+        },
+        {
+            "name":        "reply_comment",
+            "description": "回复笔记下的指定评论（前提：用户已登录），comment_id 来自 get_feed_detail 返回的评论列表",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "feed_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 的 xsecToken 字段",
+                    },
+                    "comment_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "要回复的评论ID",
+                    },
+                    "content": map[string]interface{}{
+                        "type":        "string",
+                        "description": "回复内容",
+                    },
+                },
+                "required": []string{"feed_id", "xsec_token", "comment_id", "content"},
+            },
+        },
+        {
+            "name":        "user_profile",
+            "description": "获取用户主页信息（昵称、简介、关注数、粉丝数、获赞与收藏数、标签）及用户发布的笔记列表",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "user_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "用户ID，即 Feed 中 user 的 userId 字段",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 中 user 的 xsecToken 字段",
+                    },
+                    "limit": map[string]interface{}{
+                        "type":        "integer",
+                        "description": "返回笔记数量上限（可选），默认30，超过首屏数量时滚动加载",
+                        "minimum":     1,
+                    },
+                },
+                "required": []string{"user_id"},
+            },
+        },
+        {
+            "name":        "like_feed",
+            "description": "点赞笔记，undo 为 true 时取消点赞（前提：用户已登录）。已处于目标状态时不重复操作",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "feed_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 的 xsecToken 字段",
+                    },
+                    "undo": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "是否取消点赞，默认 false",
+                    },
+                },
+                "required": []string{"feed_id", "xsec_token"},
+            },
+        },
+        {
+            "name":        "collect_feed",
+            "description": "收藏笔记，undo 为 true 时取消收藏（前提：用户已登录）。已处于目标状态时不重复操作",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "feed_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 的 xsecToken 字段",
+                    },
+                    "undo": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "是否取消收藏，默认 false",
+                    },
+                },
+                "required": []string{"feed_id", "xsec_token"},
+            },
+        },
+        {
+            "name":        "follow_user",
+            "description": "关注用户，undo 为 true 时取消关注（前提：用户已登录）。已处于目标状态时不重复操作",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "user_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "用户ID，即 Feed 中 user 的 userId 字段",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌（可选），即 Feed 中 user 的 xsecToken 字段",
+                    },
+                    "undo": map[string]interface{}{
+                        "type":        "boolean",
+                        "description": "是否取消关注，默认 false",
+                    },
+                },
+                "required": []string{"user_id"},
+            },
+        },
+        {
+            "name":        "download_feed_media",
+            "description": "下载笔记的全部原图和视频到服务端媒体目录，并保存笔记元数据 note.json，返回本地文件路径",
+            "inputSchema": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "feed_id": map[string]interface{}{
+                        "type":        "string",
+                        "description": "笔记ID",
+                    },
+                    "xsec_token": map[string]interface{}{
+                        "type":        "string",
+                        "description": "访问令牌，即 Feed 的 xsecToken 字段",
+                    },
+                },
+                "required": []string{"feed_id", "xsec_token"},
+            },
+        },
         {
             "name":        "ai_generate_publish",
             "description": "通过AI生成标题、内容、标签和封面，并可选自动发布",
@@ -311,10 +687,36 @@ func (s *AppServer) processToolCall(ctx context.Context, request *JSONRPCRequest
         result = s.handleCheckLoginStatus(ctx)
     case "publish_content":
         result = s.handlePublishContent(ctx, toolArgs)
+    case "get_note_status":
+        result = s.handleGetNoteStatus(ctx, toolArgs)
+    case "edit_note":
+        result = s.handleEditNote(ctx, toolArgs)
+    case "delete_note":
+        result = s.handleDeleteNote(ctx, toolArgs)
+    case "list_products":
+        result = s.handleListProducts(ctx)
     case "list_feeds":
-        result = s.handleListFeeds(ctx)
+        result = s.handleListFeeds(ctx, toolArgs)
+    case "list_channels":
+        result = s.handleListChannels(ctx)
     case "search_feeds":
         result = s.handleSearchFeeds(ctx, toolArgs)
+    case "get_feed_detail":
+        result = s.handleGetFeedDetail(ctx, toolArgs)
+    case "post_comment":
+        result = s.handlePostComment(ctx, toolArgs)
+    case "reply_comment":
+        result = s.handleReplyComment(ctx, toolArgs)
+    case "user_profile":
+        result = s.handleUserProfile(ctx, toolArgs)
+    case "like_feed":
+        result = s.handleLikeFeed(ctx, toolArgs)
+    case "collect_feed":
+        result = s.handleCollectFeed(ctx, toolArgs)
+    case "follow_user":
+        result = s.handleFollowUser(ctx, toolArgs)
+    case "download_feed_media":
+        result = s.handleDownloadFeedMedia(ctx, toolArgs)
     case "ai_generate_publish":
         result = s.handleAIGenerate(ctx, toolArgs)
     default:
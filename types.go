@@ -57,6 +57,8 @@ type MCPToolResult struct {
 
 // MCPContent MCP 内容
 type MCPContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`     // 图片内容，base64 编码
+	MimeType string `json:"mimeType,omitempty"` // 图片内容的 MIME 类型
 }
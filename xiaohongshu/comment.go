@@ -0,0 +1,277 @@
+package xiaohongshu
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNotLoggedIn 操作需要登录，当前未登录或登录已失效
+	ErrNotLoggedIn = errors.New("未登录或登录已失效，请先登录")
+	// ErrCommentDisabled 笔记作者关闭了评论
+	ErrCommentDisabled = errors.New("该笔记已关闭评论")
+	// ErrRateLimited 操作过于频繁，被平台限流
+	ErrRateLimited = errors.New("操作过于频繁，请稍后再试")
+	// ErrCommentNotFound 页面中未找到要回复的评论
+	ErrCommentNotFound = errors.New("未找到要回复的评论")
+)
+
+const (
+	// 评论输入框未激活时的占位区域
+	selectorCommentTrigger = `div.engage-bar div.input-box div.content-edit, div.engage-bar .not-active`
+	// 评论输入框
+	selectorCommentInput = `#content-textarea`
+	// 评论发送按钮
+	selectorCommentSubmit = `div.engage-bar button.submit`
+	// 登录弹窗
+	selectorLoginModal = `div.login-container, div.login-modal`
+
+	// commentSubmitTimeout 点击发送后等待评论出现或错误提示的最长时间
+	commentSubmitTimeout = 10 * time.Second
+
+	// 评论区关闭时的提示文案
+	selectorCommentArea    = `div.engage-bar, div.comments-container, div.comments-el`
+	patternCommentDisabled = `关闭评论|评论已关闭|不允许评论|评论功能已关闭`
+	// 笔记不存在或无法访问时的提示文案
+	selectorFeedError   = `div.error-container, div.not-found, div.feed-error, p, span`
+	patternFeedNotFound = `笔记不存在|暂时无法浏览|已被删除|内容无法展示|页面不见了`
+	// 评论列表加载到底部的标记
+	selectorCommentsEnd = `div.end-container, div.comments-end`
+	patternCommentsEnd  = `THE END|没有更多`
+)
+
+// commentIDPattern 评论ID只包含字母和数字，拼接到选择器前需校验
+var commentIDPattern = regexp.MustCompile(`^[0-9a-zA-Z]+$`)
+
+type CommentAction struct {
+	page *rod.Page
+}
+
+func NewCommentAction(page *rod.Page) *CommentAction {
+	pp := page.Timeout(60 * time.Second)
+
+	return &CommentAction{page: pp}
+}
+
+// PostComment 在笔记下发表一级评论
+func (c *CommentAction) PostComment(ctx context.Context, feedID, xsecToken, content string) error {
+	page := c.page.Context(ctx)
+
+	if err := openNoteForEngage(page, feedID, xsecToken); err != nil {
+		return err
+	}
+
+	trigger, err := page.Timeout(5 * time.Second).Element(selectorCommentTrigger)
+	if err != nil {
+		if pageHasText(page, selectorCommentArea, patternCommentDisabled) {
+			return ErrCommentDisabled
+		}
+		return errors.Wrap(err, "未找到评论输入框")
+	}
+
+	if err := trigger.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "激活评论输入框失败")
+	}
+
+	if err := submitComment(page, content); err != nil {
+		return err
+	}
+
+	logrus.Infof("已评论笔记 %s", feedID)
+	return nil
+}
+
+// ReplyComment 回复笔记下的指定评论
+func (c *CommentAction) ReplyComment(ctx context.Context, feedID, xsecToken, commentID, content string) error {
+	if !commentIDPattern.MatchString(commentID) {
+		return errors.Errorf("无效的评论ID: %q", commentID)
+	}
+
+	page := c.page.Context(ctx)
+
+	if err := openNoteForEngage(page, feedID, xsecToken); err != nil {
+		return err
+	}
+
+	comment, err := findComment(page, commentID)
+	if err != nil {
+		return err
+	}
+
+	if err := comment.ScrollIntoView(); err != nil {
+		logrus.Debugf("滚动到评论失败: %v", err)
+	}
+
+	has, replyBtn, err := comment.Has(".interactions .reply, .reply")
+	if err != nil {
+		return errors.Wrap(err, "查找回复按钮失败")
+	}
+	if !has {
+		if pageHasText(page, selectorCommentArea, patternCommentDisabled) {
+			return ErrCommentDisabled
+		}
+		return errors.Errorf("评论 %s 上未找到回复按钮", commentID)
+	}
+
+	if err := replyBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "点击回复按钮失败")
+	}
+
+	if err := submitComment(page, content); err != nil {
+		return err
+	}
+
+	logrus.Infof("已回复笔记 %s 的评论 %s", feedID, commentID)
+	return nil
+}
+
+// openNoteForEngage 打开笔记详情页，并确认页面可访问且已登录
+func openNoteForEngage(page *rod.Page, feedID, xsecToken string) error {
+	if err := page.Navigate(makeFeedDetailURL(feedID, xsecToken)); err != nil {
+		return errors.Wrap(err, "打开笔记详情页失败")
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return errors.Wrap(err, "等待笔记详情页加载失败")
+	}
+
+	if _, err := page.Timeout(15 * time.Second).Element("div.note-container, div.interaction-container"); err != nil {
+		if has, _, _ := page.Has(selectorLoginModal); has {
+			return ErrNotLoggedIn
+		}
+		if pageHasText(page, selectorFeedError, patternFeedNotFound) {
+			return ErrFeedNotFound
+		}
+		return errors.Wrap(err, "等待笔记详情页内容超时")
+	}
+
+	if has, _, err := page.Has(selectorLoginModal); err == nil && has {
+		return ErrNotLoggedIn
+	}
+
+	return nil
+}
+
+// findComment 在评论列表中查找评论，未加载时滚动评论区加载更多
+func findComment(page *rod.Page, commentID string) (*rod.Element, error) {
+	selector := "#comment-" + commentID
+
+	var comment *rod.Element
+	err := waitUntil(page.GetContext(), 15*time.Second, "评论加载", func() bool {
+		has, elem, err := page.Has(selector)
+		if err == nil && has {
+			comment = elem
+			return true
+		}
+
+		// 评论列表滚动加载，滚动到底部触发下一页
+		if _, err := page.Eval(`() => {
+			const scroller = document.querySelector('.note-scroller');
+			if (scroller) scroller.scrollTop = scroller.scrollHeight;
+		}`); err != nil {
+			logrus.Debugf("滚动评论列表失败: %v", err)
+		}
+		return false
+	})
+	if err != nil {
+		// 评论列表已加载到底部仍未找到才确定评论不存在
+		if pageHasText(page, selectorCommentsEnd, patternCommentsEnd) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, errors.Wrap(err, "评论列表未加载完成")
+	}
+
+	return comment, nil
+}
+
+// pageHasText 判断页面上是否有匹配 selector 且文本匹配正则 pattern 的元素
+func pageHasText(page *rod.Page, selector, pattern string) bool {
+	has, _, err := page.HasR(selector, pattern)
+	return err == nil && has
+}
+
+// submitComment 在已激活的评论框中输入内容并发送，等待评论出现或错误提示
+func submitComment(page *rod.Page, content string) error {
+	input, err := page.Timeout(5 * time.Second).Element(selectorCommentInput)
+	if err != nil {
+		if has, _, _ := page.Has(selectorLoginModal); has {
+			return ErrNotLoggedIn
+		}
+		return errors.Wrap(err, "未找到评论输入框")
+	}
+
+	if err := input.Focus(); err != nil {
+		return errors.Wrap(err, "聚焦评论输入框失败")
+	}
+
+	if err := page.InsertText(content); err != nil {
+		return errors.Wrap(err, "输入评论内容失败")
+	}
+
+	submit, err := page.Timeout(5 * time.Second).Element(selectorCommentSubmit)
+	if err != nil {
+		return errors.Wrap(err, "未找到评论发送按钮")
+	}
+
+	if err := submit.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "点击发送按钮失败")
+	}
+
+	var failure error
+	err = waitUntil(page.GetContext(), commentSubmitTimeout, "评论发送结果", func() bool {
+		if failure = engageFailure(page); failure != nil {
+			return true
+		}
+
+		// 发送成功后输入框被清空
+		res, err := input.Eval(`() => (this.innerText || this.value || '').trim()`)
+		return err == nil && res.Value.String() == ""
+	})
+	if failure != nil {
+		return failure
+	}
+
+	return err
+}
+
+// engageFailure 检查页面上的登录弹窗和错误提示，没有失败迹象时返回 nil
+func engageFailure(page *rod.Page) error {
+	if has, _, _ := page.Has(selectorLoginModal); has {
+		return ErrNotLoggedIn
+	}
+
+	if has, toast, _ := page.Has(".d-toast, .toast-container, .reds-toast"); has {
+		if text, err := toast.Text(); err == nil {
+			return classifyEngageFailure(text)
+		}
+	}
+
+	return nil
+}
+
+// classifyEngageFailure 根据页面提示文案识别评论、点赞等互动操作的失败原因，无法识别时返回 nil
+func classifyEngageFailure(text string) error {
+	text = strings.TrimSpace(text)
+
+	switch {
+	case text == "":
+		return nil
+	case strings.Contains(text, "登录"):
+		return ErrNotLoggedIn
+	case strings.Contains(text, "关闭评论"), strings.Contains(text, "评论已关闭"), strings.Contains(text, "不允许评论"):
+		return ErrCommentDisabled
+	case strings.Contains(text, "频繁"), strings.Contains(text, "太快"), strings.Contains(text, "稍后再试"):
+		return ErrRateLimited
+	case strings.Contains(text, "失败"), strings.Contains(text, "违规"):
+		return errors.Errorf("操作失败: %s", text)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,27 @@
+package xiaohongshu
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyEngageFailure(t *testing.T) {
+	assert.ErrorIs(t, classifyEngageFailure("请先登录"), ErrNotLoggedIn)
+	assert.ErrorIs(t, classifyEngageFailure("作者已关闭评论"), ErrCommentDisabled)
+	assert.ErrorIs(t, classifyEngageFailure("操作太频繁，请稍后再试"), ErrRateLimited)
+	assert.Error(t, classifyEngageFailure("评论发送失败"))
+
+	assert.NoError(t, classifyEngageFailure(""))
+	assert.NoError(t, classifyEngageFailure("评论成功"))
+}
+
+func TestReplyCommentRejectsInvalidCommentID(t *testing.T) {
+	action := &CommentAction{}
+
+	for _, commentID := range []string{"", "abc def", "1, #evil", "a]b"} {
+		err := action.ReplyComment(context.Background(), "feed", "token", commentID, "回复")
+		assert.ErrorContains(t, err, "无效的评论ID", commentID)
+	}
+}
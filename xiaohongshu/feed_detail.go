@@ -0,0 +1,129 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrFeedNotFound 笔记不存在、已删除或 xsec_token 无效
+var ErrFeedNotFound = errors.New("笔记不存在或无法访问")
+
+// commentsLoadTimeout 等待第一页评论加载的最长时间，超时后返回已有内容
+const commentsLoadTimeout = 5 * time.Second
+
+// noteDetailEntry 对应 __INITIAL_STATE__.note.noteDetailMap 中的单条记录
+type noteDetailEntry struct {
+	Note     FeedDetail  `json:"note"`
+	Comments CommentList `json:"comments"`
+}
+
+type NoteDetailAction struct {
+	page *rod.Page
+}
+
+func NewNoteDetailAction(page *rod.Page) *NoteDetailAction {
+	pp := page.Timeout(60 * time.Second)
+
+	return &NoteDetailAction{page: pp}
+}
+
+// GetFeedDetail 打开笔记详情页，读取笔记正文、图片、标签和第一页评论
+func (n *NoteDetailAction) GetFeedDetail(ctx context.Context, feedID, xsecToken string) (*FeedDetailResponse, error) {
+	page := n.page.Context(ctx)
+
+	collector := startResponseCollector(page)
+	defer collector.Stop()
+
+	if err := page.Navigate(makeFeedDetailURL(feedID, xsecToken)); err != nil {
+		return nil, errors.Wrap(err, "打开笔记详情页失败")
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return nil, errors.Wrap(err, "等待笔记详情页加载失败")
+	}
+
+	if err := waitUntil(ctx, 15*time.Second, "页面数据加载", func() bool {
+		res, err := page.Eval(`() => window.__INITIAL_STATE__ !== undefined`)
+		return err == nil && res.Value.Bool()
+	}); err != nil {
+		return nil, err
+	}
+
+	// 评论在页面渲染后异步加载
+	if err := waitUntil(ctx, commentsLoadTimeout, "评论加载", func() bool {
+		res, err := page.Eval(`(id) => {
+			const entry = window.__INITIAL_STATE__?.note?.noteDetailMap?.[id];
+			const comments = entry && entry.comments;
+			return !!comments && (comments.firstRequestFinish || (comments.list && comments.list.length > 0));
+		}`, feedID)
+		return err == nil && res.Value.Bool()
+	}); err != nil {
+		logrus.Debugf("%v，返回不含评论的笔记详情", err)
+	}
+
+	res, err := page.Eval(`() => {
+		const map = window.__INITIAL_STATE__?.note?.noteDetailMap;
+		return map ? JSON.stringify(map) : "";
+	}`)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取笔记详情数据失败")
+	}
+
+	detail, err := parseFeedDetail([]byte(res.Value.String()), feedID)
+	if errors.Is(err, ErrFeedNotFound) {
+		// 服务端渲染的状态中没有该笔记时，使用详情接口返回的数据
+		if note := collector.NoteDetail(feedID); note != nil {
+			detail, err = &FeedDetailResponse{Note: *note}, nil
+			detail.normalize()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(detail.Comments.List) == 0 {
+		detail.Comments = collector.Comments()
+	}
+
+	return detail, nil
+}
+
+// parseFeedDetail 从 noteDetailMap 的 JSON 中解析指定笔记的详情
+func parseFeedDetail(data []byte, feedID string) (*FeedDetailResponse, error) {
+	if len(data) == 0 {
+		return nil, ErrFeedNotFound
+	}
+
+	var detailMap map[string]noteDetailEntry
+	if err := json.Unmarshal(data, &detailMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal noteDetailMap: %w", err)
+	}
+
+	entry, ok := detailMap[feedID]
+	if !ok || entry.Note.NoteID == "" {
+		return nil, ErrFeedNotFound
+	}
+
+	detail := &FeedDetailResponse{
+		Note:     entry.Note,
+		Comments: entry.Comments,
+	}
+	detail.normalize()
+
+	return detail, nil
+}
+
+func makeFeedDetailURL(feedID, xsecToken string) string {
+	values := url.Values{}
+	values.Set("xsec_token", xsecToken)
+	values.Set("xsec_source", "pc_feed")
+
+	return fmt.Sprintf("https://www.xiaohongshu.com/explore/%s?%s", url.PathEscape(feedID), values.Encode())
+}
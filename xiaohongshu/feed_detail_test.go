@@ -0,0 +1,76 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const noteDetailMapJSON = `{
+	"66a1b2c3d4e5f6a7b8c9d0e1": {
+		"note": {
+			"noteId": "66a1b2c3d4e5f6a7b8c9d0e1",
+			"xsecToken": "ABtoken",
+			"title": "春季养生茶推荐",
+			"desc": "分享几款好喝的养生茶～ #养生[话题]#",
+			"type": "normal",
+			"time": 1712000000000,
+			"ipLocation": "湖南",
+			"user": {"userId": "u1", "nickname": "茶小白"},
+			"interactInfo": {"liked": true, "likedCount": "1.2万", "commentCount": "36"},
+			"imageList": [
+				{"width": 1080, "height": 1440, "urlDefault": "https://sns-webpic.example.com/1.jpg"},
+				{"width": 1080, "height": 1440, "urlDefault": "https://sns-webpic.example.com/2.jpg"}
+			],
+			"tagList": [{"id": "t1", "name": "养生", "type": "topic"}]
+		},
+		"comments": {
+			"list": [
+				{
+					"id": "c1",
+					"content": "求链接",
+					"likeCount": "3",
+					"createTime": 1712000100000,
+					"userInfo": {"userId": "u2", "nickname": "路人"},
+					"subCommentCount": "1",
+					"subComments": [{"id": "c2", "content": "已私信", "userInfo": {"userId": "u1"}}]
+				}
+			],
+			"cursor": "c1",
+			"hasMore": true
+		}
+	}
+}`
+
+func TestParseFeedDetail(t *testing.T) {
+	detail, err := parseFeedDetail([]byte(noteDetailMapJSON), "66a1b2c3d4e5f6a7b8c9d0e1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "春季养生茶推荐", detail.Note.Title)
+	assert.Equal(t, int64(1712000000000), detail.Note.Time)
+	assert.Equal(t, "茶小白", detail.Note.User.Nickname)
+	assert.True(t, detail.Note.InteractInfo.Liked)
+	require.Len(t, detail.Note.ImageList, 2)
+	assert.Equal(t, "https://sns-webpic.example.com/2.jpg", detail.Note.ImageList[1].URLDefault)
+	require.Len(t, detail.Note.TagList, 1)
+	assert.Equal(t, "养生", detail.Note.TagList[0].Name)
+
+	require.Len(t, detail.Comments.List, 1)
+	assert.True(t, detail.Comments.HasMore)
+	assert.Equal(t, "求链接", detail.Comments.List[0].Content)
+	require.Len(t, detail.Comments.List[0].SubComments, 1)
+	assert.Equal(t, "已私信", detail.Comments.List[0].SubComments[0].Content)
+
+	_, err = parseFeedDetail([]byte(noteDetailMapJSON), "not-exist")
+	assert.ErrorIs(t, err, ErrFeedNotFound)
+
+	_, err = parseFeedDetail(nil, "66a1b2c3d4e5f6a7b8c9d0e1")
+	assert.ErrorIs(t, err, ErrFeedNotFound)
+}
+
+func TestMakeFeedDetailURL(t *testing.T) {
+	assert.Equal(t,
+		"https://www.xiaohongshu.com/explore/abc123?xsec_source=pc_feed&xsec_token=AB%3D%3D",
+		makeFeedDetailURL("abc123", "AB=="))
+}
@@ -0,0 +1,162 @@
+package xiaohongshu
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 页面展示的计数带有单位后缀
+var countUnits = []struct {
+	suffix string
+	scale  float64
+}{
+	{"亿", 1e8},
+	{"万", 1e4},
+	{"w", 1e4},
+	{"k", 1e3},
+}
+
+// parseCount 将页面展示的计数（如 "1.2万"、"10万+"、"3.5k"、"1,024"）转换为整数，
+// 无法解析时（如空字符串或 "赞"）返回 0
+func parseCount(s string) int64 {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "+")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0
+	}
+
+	scale := 1.0
+	for _, unit := range countUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			scale = unit.scale
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+
+	return int64(math.Round(n * scale))
+}
+
+// normalize 合并昵称字段，页面不同位置分别使用 nickname 和 nickName
+func (u *User) normalize() {
+	if u.Nickname == "" {
+		u.Nickname = u.NickName
+	}
+	u.NickName = ""
+}
+
+// normalize 根据原始计数字符串填充数值字段
+func (i *InteractInfo) normalize() {
+	i.LikedNum = parseCount(i.LikedCount)
+	i.SharedNum = parseCount(i.SharedCount)
+	i.CommentNum = parseCount(i.CommentCount)
+	i.CollectedNum = parseCount(i.CollectedCount)
+}
+
+// BestURL 返回图片的最佳地址：优先 WB_DFT 场景的原图，其次默认地址，最后预览图
+func (c Cover) BestURL() string {
+	for _, info := range c.InfoList {
+		if info.ImageScene == "WB_DFT" && info.URL != "" {
+			return info.URL
+		}
+	}
+
+	for _, url := range []string{c.URLDefault, c.URL} {
+		if url != "" {
+			return url
+		}
+	}
+
+	for _, info := range c.InfoList {
+		if info.URL != "" {
+			return info.URL
+		}
+	}
+
+	return c.URLPre
+}
+
+// BestURL 返回详情图片的最佳地址，规则与 Cover.BestURL 相同
+func (img DetailImageInfo) BestURL() string {
+	return Cover{URLDefault: img.URLDefault, URLPre: img.URLPre, InfoList: img.InfoList}.BestURL()
+}
+
+// BestStream 返回清晰度最高的视频流，清晰度相同时优先兼容性更好的 H.264
+func (v *Video) BestStream() *VideoStream {
+	if v == nil || v.Media == nil {
+		return nil
+	}
+
+	var best *VideoStream
+	for _, streams := range [][]VideoStream{v.Media.Stream.H264, v.Media.Stream.H265} {
+		for i := range streams {
+			s := &streams[i]
+			if s.MasterURL == "" {
+				continue
+			}
+			if best == nil || s.Width*s.Height > best.Width*best.Height {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// normalize 填充计数、昵称、图片列表和笔记地址等派生字段
+func (f *Feed) normalize() {
+	card := &f.NoteCard
+	card.User.normalize()
+	card.InteractInfo.normalize()
+
+	card.Images = card.Images[:0]
+	for _, img := range card.ImageList {
+		if url := img.BestURL(); url != "" {
+			card.Images = append(card.Images, url)
+		}
+	}
+	// 首页卡片只有封面，没有完整图片列表
+	if len(card.Images) == 0 {
+		if url := card.Cover.BestURL(); url != "" {
+			card.Images = append(card.Images, url)
+		}
+	}
+
+	if f.ID != "" && f.XsecToken != "" {
+		f.URL = makeFeedDetailURL(f.ID, f.XsecToken)
+	}
+}
+
+func normalizeFeeds(feeds []Feed) []Feed {
+	for i := range feeds {
+		feeds[i].normalize()
+	}
+	return feeds
+}
+
+func (c *Comment) normalize() {
+	c.UserInfo.normalize()
+	c.LikeNum = parseCount(c.LikeCount)
+	for i := range c.SubComments {
+		c.SubComments[i].normalize()
+	}
+}
+
+// normalize 填充笔记详情及评论中的派生字段
+func (r *FeedDetailResponse) normalize() {
+	r.Note.User.normalize()
+	r.Note.InteractInfo.normalize()
+	if r.Note.NoteID != "" && r.Note.XsecToken != "" {
+		r.Note.URL = makeFeedDetailURL(r.Note.NoteID, r.Note.XsecToken)
+	}
+
+	for i := range r.Comments.List {
+		r.Comments.List[i].normalize()
+	}
+}
@@ -0,0 +1,116 @@
+package xiaohongshu
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"0", 0},
+		{"892", 892},
+		{"3,456", 3456},
+		{"1.2万", 12000},
+		{"10万+", 100000},
+		{"2.3亿", 230000000},
+		{"1.5k", 1500},
+		{"1.5K", 1500},
+		{"3w", 30000},
+		{" 12 ", 12},
+		{"赞", 0},
+		{"-1", 0},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseCount(tt.in), tt.in)
+	}
+}
+
+func TestCoverBestURL(t *testing.T) {
+	cover := Cover{
+		URLPre:     "pre",
+		URLDefault: "default",
+		InfoList: []ImageInfo{
+			{ImageScene: "WB_PRV", URL: "prv"},
+			{ImageScene: "WB_DFT", URL: "dft"},
+		},
+	}
+	assert.Equal(t, "dft", cover.BestURL())
+
+	cover.InfoList = cover.InfoList[:1]
+	assert.Equal(t, "default", cover.BestURL())
+
+	cover.URLDefault = ""
+	assert.Equal(t, "prv", cover.BestURL())
+
+	assert.Equal(t, "pre", Cover{URLPre: "pre"}.BestURL())
+	assert.Empty(t, Cover{}.BestURL())
+}
+
+func TestParseHomeStateFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/explore_state.json")
+	require.NoError(t, err)
+
+	feeds, err := parseHomeState(data)
+	require.NoError(t, err)
+	require.Len(t, feeds, 3)
+
+	first := feeds[0]
+	assert.Equal(t, "6650f1a2000000001e03a4b1", first.ID)
+	assert.Equal(t, "茶小白", first.NoteCard.User.Nickname, "nickName 合并到 nickname")
+	assert.Empty(t, first.NoteCard.User.NickName)
+	assert.Equal(t, int64(12000), first.NoteCard.InteractInfo.LikedNum)
+	assert.Equal(t, []string{"http://sns-webpic-qc.xhscdn.com/202406/1040g008dft!nc_n_webp_mw_1"}, first.NoteCard.Images)
+	assert.Equal(t, "https://www.xiaohongshu.com/explore/6650f1a2000000001e03a4b1?xsec_source=pc_feed&xsec_token=ABx9cQ2mZ1w-explore1%3D", first.URL)
+
+	video := feeds[1]
+	assert.Equal(t, "Tea Lab", video.NoteCard.User.Nickname)
+	assert.Equal(t, int64(100000), video.NoteCard.InteractInfo.LikedNum)
+	require.NotNil(t, video.NoteCard.Video)
+	assert.Equal(t, 186, video.NoteCard.Video.Capa.Duration)
+	assert.Equal(t, []string{"http://sns-webpic-qc.xhscdn.com/202406/1040g00video!nc_n_webp_mw_1"}, video.NoteCard.Images)
+
+	third := feeds[2]
+	assert.Equal(t, "打工人阿May", third.NoteCard.User.Nickname)
+	assert.Equal(t, int64(0), third.NoteCard.InteractInfo.LikedNum, "\"赞\" 表示没有点赞")
+
+	_, err = parseHomeState(nil)
+	assert.Error(t, err)
+}
+
+func TestParseSearchStateFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/search_state.json")
+	require.NoError(t, err)
+
+	feeds, err := parseSearchState(data)
+	require.NoError(t, err)
+	require.Len(t, feeds, 3)
+
+	first := feeds[0]
+	info := first.NoteCard.InteractInfo
+	assert.Equal(t, int64(12000), info.LikedNum)
+	assert.Equal(t, int64(3456), info.CollectedNum)
+	assert.Equal(t, int64(892), info.CommentNum)
+	assert.Equal(t, int64(1500), info.SharedNum)
+	assert.Equal(t, []string{
+		"http://sns-webpic-qc.xhscdn.com/202406/1040g008img1!nc_n_webp_mw_1",
+		"http://sns-webpic-qc.xhscdn.com/202406/1040g008img2!nc_n_webp_mw_1",
+	}, first.NoteCard.Images, "有图片列表时使用完整图片列表而不是封面")
+	assert.Contains(t, first.URL, "xsec_token=ABsearch1%3D")
+
+	assert.Equal(t, "hot_query", feeds[1].ModelType)
+	assert.Empty(t, feeds[1].NoteCard.Images)
+	assert.Empty(t, feeds[1].URL, "没有 xsecToken 时不生成地址")
+
+	video := feeds[2]
+	assert.Equal(t, "养生局局长", video.NoteCard.User.Nickname)
+	assert.Equal(t, int64(230000000), video.NoteCard.InteractInfo.LikedNum)
+	assert.Equal(t, []string{"http://sns-webpic-qc.xhscdn.com/202406/1040g00vid2!nc_n_webp_prv_1"}, video.NoteCard.Images)
+}
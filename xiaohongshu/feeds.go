@@ -4,11 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+const (
+	// 首页频道标签
+	selectorChannel = `#channel-container .channel`
+	// maxFeedsScrolls 加载频道笔记时最多滚动的次数
+	maxFeedsScrolls = 20
+)
+
+// ErrChannelNotFound 首页没有名称或ID匹配的频道
+var ErrChannelNotFound = errors.New("未找到频道")
+
+// Channel 首页频道
+type Channel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// FeedsListOptions 首页笔记列表参数
+type FeedsListOptions struct {
+	Channel string // 频道名称或ID，为空时为默认的推荐频道
+	Limit   int    // 返回笔记数量，超过首屏数量时滚动加载，<=0 时只返回首屏
+}
+
 type FeedsListAction struct {
 	page *rod.Page
 }
@@ -40,16 +66,162 @@ func (f *FeedsListAction) GetFeedsList(ctx context.Context) ([]Feed, error) {
 		return "";
 	}`).String()
 
-	if result == "" {
+	return parseHomeState([]byte(result))
+}
+
+// parseHomeState 从首页 __INITIAL_STATE__ 的 JSON 中解析 feed.feeds._value
+func parseHomeState(data []byte) ([]Feed, error) {
+	if len(data) == 0 {
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
 	}
 
 	// 解析完整的 InitialState
 	var state FeedsResult
-	if err := json.Unmarshal([]byte(result), &state); err != nil {
+	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal __INITIAL_STATE__: %w", err)
 	}
 
-	// 返回 feed.feeds._value
-	return state.Feed.Feeds.Value, nil
+	return normalizeFeeds(state.Feed.Feeds.Value), nil
+}
+
+// ListChannels 读取首页的频道列表
+func (f *FeedsListAction) ListChannels(ctx context.Context) ([]Channel, error) {
+	page := f.page.Context(ctx)
+
+	if _, err := page.Timeout(10 * time.Second).Element(selectorChannel); err != nil {
+		return nil, errors.Wrap(err, "首页频道列表未加载")
+	}
+
+	res, err := page.Eval(`(selector) => Array.from(document.querySelectorAll(selector)).map(el => ({
+		id: el.id || el.getAttribute('data-channel-id') || '',
+		name: (el.innerText || '').trim(),
+	}))`, selectorChannel)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取频道列表失败")
+	}
+
+	var channels []Channel
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &channels); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal channels: %w", err)
+	}
+
+	return channels, nil
+}
+
+// GetChannelFeeds 切换到指定频道并读取笔记，数量不足时滚动加载，按 Feed.ID 去重
+func (f *FeedsListAction) GetChannelFeeds(ctx context.Context, opts FeedsListOptions) ([]Feed, error) {
+	page := f.page.Context(ctx)
+
+	if opts.Channel != "" {
+		if err := f.selectChannel(ctx, opts.Channel); err != nil {
+			return nil, err
+		}
+	}
+
+	collector := startResponseCollector(page)
+	defer collector.Stop()
+
+	seen := make(map[string]bool)
+	initial, err := readHomeFeeds(page)
+	if err != nil {
+		return nil, err
+	}
+	feeds := mergeFeeds(nil, seen, initial)
+
+	for i := 0; len(feeds) < opts.Limit && i < maxFeedsScrolls; i++ {
+		loaded := len(feeds)
+
+		if _, err := page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			logrus.Debugf("滚动首页失败: %v", err)
+			break
+		}
+
+		if err := waitUntil(ctx, 5*time.Second, "首页笔记加载", func() bool {
+			if more, err := readHomeFeeds(page); err == nil {
+				feeds = mergeFeeds(feeds, seen, more)
+			}
+			feeds = mergeFeeds(feeds, seen, collector.Feeds(apiHomeFeed))
+			return len(feeds) > loaded
+		}); err != nil {
+			logrus.Debugf("%v，共加载%d篇笔记", err, loaded)
+			break
+		}
+	}
+
+	if opts.Limit > 0 && len(feeds) > opts.Limit {
+		feeds = feeds[:opts.Limit]
+	}
+
+	return feeds, nil
+}
+
+// selectChannel 点击名称或ID匹配的频道，等待笔记列表刷新
+func (f *FeedsListAction) selectChannel(ctx context.Context, nameOrID string) error {
+	page := f.page.Context(ctx)
+
+	channels, err := f.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := matchChannel(channels, nameOrID)
+	if index < 0 {
+		return errors.Wrapf(ErrChannelNotFound, "%s", nameOrID)
+	}
+
+	elems, err := page.Elements(selectorChannel)
+	if err != nil || index >= len(elems) {
+		return errors.New("频道列表已变化，请重试")
+	}
+
+	before, _ := readHomeFeeds(page)
+
+	if err := elems[index].Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrapf(err, "切换到频道 %s 失败", channels[index].Name)
+	}
+
+	if err := waitUntil(ctx, 10*time.Second, "频道笔记刷新", func() bool {
+		after, err := readHomeFeeds(page)
+		if err != nil || len(after) == 0 {
+			return false
+		}
+		return len(before) == 0 || after[0].ID != before[0].ID
+	}); err != nil {
+		// 未刷新时读到的是原频道的笔记，不能当作所选频道的结果返回
+		return errors.Wrapf(err, "切换到频道 %s 后笔记未刷新", channels[index].Name)
+	}
+
+	logrus.Infof("已切换到频道: %s", channels[index].Name)
+	return nil
+}
+
+// matchChannel 按ID或名称查找频道，返回下标，未找到返回 -1
+func matchChannel(channels []Channel, nameOrID string) int {
+	nameOrID = strings.TrimSpace(nameOrID)
+
+	for i, channel := range channels {
+		if channel.ID != "" && channel.ID == nameOrID {
+			return i
+		}
+	}
+	for i, channel := range channels {
+		if channel.Name == nameOrID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// readHomeFeeds 读取首页状态中当前的笔记列表
+func readHomeFeeds(page *rod.Page) ([]Feed, error) {
+	result, err := page.Eval(`() => {
+		const feeds = window.__INITIAL_STATE__?.feed?.feeds;
+		return feeds ? JSON.stringify({feed: {feeds}}) : "";
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHomeState([]byte(result.Value.String()))
 }
@@ -83,3 +83,16 @@ func TestGetFeedsList(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchChannel(t *testing.T) {
+	channels := []Channel{
+		{ID: "homefeed_recommend", Name: "推荐"},
+		{ID: "homefeed.fashion_v3", Name: "穿搭"},
+		{ID: "homefeed.food_v3", Name: "美食"},
+	}
+
+	require.Equal(t, 1, matchChannel(channels, "穿搭"))
+	require.Equal(t, 2, matchChannel(channels, "homefeed.food_v3"))
+	require.Equal(t, 0, matchChannel(channels, " 推荐 "))
+	require.Equal(t, -1, matchChannel(channels, "旅行"))
+}
@@ -0,0 +1,236 @@
+package xiaohongshu
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// 笔记详情页底部的点赞、收藏按钮
+	selectorLikeButton    = `div.engage-bar .interact-container .like-wrapper`
+	selectorCollectButton = `div.engage-bar .interact-container .collect-wrapper`
+	// 用户主页的关注按钮
+	selectorFollowButton = `div.user-info button.follow-button, div.user-info button.follow, div.info-part button`
+
+	// interactVerifyTimeout 点击后等待状态变化的最长时间
+	interactVerifyTimeout = 5 * time.Second
+)
+
+// InteractResult 互动操作结果
+type InteractResult struct {
+	Target  string `json:"target"`  // 笔记ID或用户ID
+	Action  string `json:"action"`  // like、unlike、collect、uncollect、follow、unfollow
+	State   bool   `json:"state"`   // 操作后的状态
+	Changed bool   `json:"changed"` // 为 false 表示操作前已处于目标状态
+}
+
+type InteractAction struct {
+	page *rod.Page
+}
+
+func NewInteractAction(page *rod.Page) *InteractAction {
+	pp := page.Timeout(60 * time.Second)
+
+	return &InteractAction{page: pp}
+}
+
+// Like 点赞（like 为 true）或取消点赞笔记
+func (a *InteractAction) Like(ctx context.Context, feedID, xsecToken string, like bool) (*InteractResult, error) {
+	action := "like"
+	if !like {
+		action = "unlike"
+	}
+
+	return a.toggleNoteState(ctx, feedID, xsecToken, action, "liked", selectorLikeButton, like)
+}
+
+// Collect 收藏（collect 为 true）或取消收藏笔记
+func (a *InteractAction) Collect(ctx context.Context, feedID, xsecToken string, collect bool) (*InteractResult, error) {
+	action := "collect"
+	if !collect {
+		action = "uncollect"
+	}
+
+	return a.toggleNoteState(ctx, feedID, xsecToken, action, "collected", selectorCollectButton, collect)
+}
+
+// toggleNoteState 读取笔记的互动状态，与目标不一致时点击按钮并等待状态变化
+func (a *InteractAction) toggleNoteState(ctx context.Context, feedID, xsecToken, action, field, selector string, target bool) (*InteractResult, error) {
+	page := a.page.Context(ctx)
+
+	if err := openNoteForEngage(page, feedID, xsecToken); err != nil {
+		return nil, err
+	}
+
+	result := &InteractResult{Target: feedID, Action: action}
+
+	current, err := noteInteractState(page, feedID, field)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == target {
+		result.State = current
+		return result, nil
+	}
+
+	button, err := page.Timeout(5 * time.Second).Element(selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "未找到%s按钮", action)
+	}
+
+	if err := button.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return nil, errors.Wrapf(err, "点击%s按钮失败", action)
+	}
+
+	if err := waitForInteractState(page, func() (bool, error) {
+		return noteInteractState(page, feedID, field)
+	}, target); err != nil {
+		return nil, err
+	}
+
+	result.State = target
+	result.Changed = true
+	logrus.Infof("%s 笔记 %s 成功", action, feedID)
+	return result, nil
+}
+
+// Follow 关注（follow 为 true）或取消关注用户
+func (a *InteractAction) Follow(ctx context.Context, userID, xsecToken string, follow bool) (*InteractResult, error) {
+	page := a.page.Context(ctx)
+
+	action := "follow"
+	if !follow {
+		action = "unfollow"
+	}
+
+	if err := page.Navigate(makeUserProfileURL(userID, xsecToken)); err != nil {
+		return nil, errors.Wrap(err, "打开用户主页失败")
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return nil, errors.Wrap(err, "等待用户主页加载失败")
+	}
+
+	if has, _, err := page.Has(selectorLoginModal); err == nil && has {
+		return nil, ErrNotLoggedIn
+	}
+
+	button, err := page.Timeout(15 * time.Second).Element(selectorFollowButton)
+	if err != nil {
+		return nil, errors.Wrap(err, "未找到关注按钮，用户可能不存在")
+	}
+
+	// 点击后按钮可能被重新渲染，每次都重新查询，避免读取已脱离页面的旧元素
+	followState := func() (bool, error) {
+		has, current, err := page.Has(selectorFollowButton)
+		if err != nil {
+			return false, err
+		}
+		if !has {
+			return false, errors.New("关注按钮已不在页面上")
+		}
+		text, err := current.Text()
+		if err != nil {
+			return false, err
+		}
+		return isFollowingText(text), nil
+	}
+
+	result := &InteractResult{Target: userID, Action: action}
+
+	current, err := followState()
+	if err != nil {
+		return nil, errors.Wrap(err, "读取关注状态失败")
+	}
+
+	if current == follow {
+		result.State = current
+		return result, nil
+	}
+
+	if err := button.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return nil, errors.Wrapf(err, "点击%s按钮失败", action)
+	}
+
+	// 取消关注时会弹出确认框
+	if !follow {
+		if confirm, err := page.Timeout(3*time.Second).ElementR("div.d-modal button, div.reds-modal button", "不再关注|确定|确认"); err == nil {
+			if err := confirm.Click(proto.InputMouseButtonLeft, 1); err != nil {
+				return nil, errors.Wrap(err, "确认取消关注失败")
+			}
+		}
+	}
+
+	if err := waitForInteractState(page, followState, follow); err != nil {
+		return nil, err
+	}
+
+	result.State = follow
+	result.Changed = true
+	logrus.Infof("%s 用户 %s 成功", action, userID)
+	return result, nil
+}
+
+// noteInteractState 从 __INITIAL_STATE__ 读取笔记的互动状态字段（liked、collected）
+func noteInteractState(page *rod.Page, feedID, field string) (bool, error) {
+	res, err := page.Eval(`(id, field) => {
+		const entry = window.__INITIAL_STATE__?.note?.noteDetailMap?.[id];
+		const info = entry && entry.note && entry.note.interactInfo;
+		return info ? !!info[field] : null;
+	}`, feedID, field)
+	if err != nil {
+		return false, errors.Wrap(err, "读取互动状态失败")
+	}
+
+	if res.Value.Nil() {
+		return false, ErrFeedNotFound
+	}
+
+	return res.Value.Bool(), nil
+}
+
+// waitForInteractState 等待状态变为目标值，期间出现登录弹窗或限流提示时提前返回
+func waitForInteractState(page *rod.Page, state func() (bool, error), target bool) error {
+	var failure error
+	err := waitUntil(page.GetContext(), interactVerifyTimeout, "互动状态更新", func() bool {
+		if failure = engageFailure(page); failure != nil {
+			return true
+		}
+
+		current, err := state()
+		return err == nil && current == target
+	})
+	if failure != nil {
+		return failure
+	}
+
+	return err
+}
+
+// isFollowingText 根据关注按钮文案判断是否已关注
+func isFollowingText(text string) bool {
+	text = strings.TrimSpace(text)
+	return strings.Contains(text, "已关注") || strings.Contains(text, "相互关注") || strings.Contains(text, "互相关注")
+}
+
+func makeUserProfileURL(userID, xsecToken string) string {
+	profileURL := fmt.Sprintf("https://www.xiaohongshu.com/user/profile/%s", url.PathEscape(userID))
+	if xsecToken == "" {
+		return profileURL
+	}
+
+	values := url.Values{}
+	values.Set("xsec_token", xsecToken)
+	values.Set("xsec_source", "pc_note")
+
+	return profileURL + "?" + values.Encode()
+}
@@ -0,0 +1,21 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsFollowingText(t *testing.T) {
+	assert.True(t, isFollowingText("已关注"))
+	assert.True(t, isFollowingText(" 相互关注 "))
+	assert.False(t, isFollowingText("关注"))
+	assert.False(t, isFollowingText("回关"))
+}
+
+func TestMakeUserProfileURL(t *testing.T) {
+	assert.Equal(t, "https://www.xiaohongshu.com/user/profile/u1", makeUserProfileURL("u1", ""))
+	assert.Equal(t,
+		"https://www.xiaohongshu.com/user/profile/u1?xsec_source=pc_note&xsec_token=AB",
+		makeUserProfileURL("u1", "AB"))
+}
@@ -0,0 +1,152 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+)
+
+// mediaMetadataFile 媒体目录中的笔记元数据文件名
+const mediaMetadataFile = "note.json"
+
+// ErrNoMedia 笔记中没有可下载的图片或视频
+var ErrNoMedia = errors.New("笔记中没有可下载的图片或视频")
+
+// mediaDirPattern 笔记ID只包含字母和数字，用作目录名前需校验
+var mediaDirPattern = regexp.MustCompile(`^[0-9a-zA-Z]+$`)
+
+// MediaFile 已下载到本地的媒体文件
+type MediaFile struct {
+	Type string `json:"type"` // image、video
+	URL  string `json:"url"`
+	Path string `json:"path"`
+	Size int64  `json:"size"` // 字节数
+}
+
+// MediaArchive 笔记媒体归档结果
+type MediaArchive struct {
+	NoteID   string      `json:"note_id"`
+	Dir      string      `json:"dir"`
+	Metadata string      `json:"metadata"` // 笔记元数据文件路径
+	Files    []MediaFile `json:"files"`
+}
+
+// mediaMetadata 写入 note.json 的内容
+type mediaMetadata struct {
+	Note         FeedDetail  `json:"note"`
+	Files        []MediaFile `json:"files"`
+	DownloadedAt time.Time   `json:"downloaded_at"`
+}
+
+type MediaDownloadAction struct {
+	page *rod.Page
+}
+
+func NewMediaDownloadAction(page *rod.Page) *MediaDownloadAction {
+	pp := page.Timeout(60 * time.Second)
+
+	return &MediaDownloadAction{page: pp}
+}
+
+// DownloadNoteMedia 读取笔记详情，将全部图片（最佳画质）和视频下载到 baseDir/<笔记ID>/，
+// 并在同一目录写入笔记元数据 note.json。
+func (m *MediaDownloadAction) DownloadNoteMedia(ctx context.Context, feedID, xsecToken, baseDir string) (*MediaArchive, error) {
+	// 目录名使用请求中校验过的笔记ID，不使用页面返回的数据，避免写到 baseDir 之外或直接写入 baseDir
+	if !mediaDirPattern.MatchString(feedID) {
+		return nil, errors.Errorf("无效的笔记ID: %q", feedID)
+	}
+
+	detail, err := NewNoteDetailAction(m.page).GetFeedDetail(ctx, feedID, xsecToken)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(baseDir, feedID)
+	return downloadNoteMedia(ctx, detail.Note, dir, downloader.DefaultURLPolicy())
+}
+
+// downloadNoteMedia 按 policy 限制下载地址，将笔记中的图片和视频下载到 dir，并写入元数据
+func downloadNoteMedia(ctx context.Context, note FeedDetail, dir string, policy downloader.URLPolicy) (*MediaArchive, error) {
+	urls := noteImageURLs(note)
+	stream := note.Video.BestStream()
+	if len(urls) == 0 && stream == nil {
+		return nil, ErrNoMedia
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建媒体目录失败")
+	}
+	d := downloader.NewImageDownloaderWithPolicy(dir, policy)
+
+	archive := &MediaArchive{
+		NoteID:   note.NoteID,
+		Dir:      dir,
+		Metadata: filepath.Join(dir, mediaMetadataFile),
+	}
+
+	for i, url := range urls {
+		path, size, err := d.DownloadFile(ctx, url, fmt.Sprintf("image_%02d", i+1))
+		if err != nil {
+			return nil, errors.Wrapf(err, "下载第%d张图片失败", i+1)
+		}
+		archive.Files = append(archive.Files, MediaFile{Type: "image", URL: url, Path: path, Size: size})
+	}
+
+	if stream != nil {
+		file, err := downloadVideoStream(ctx, d, stream)
+		if err != nil {
+			return nil, err
+		}
+		archive.Files = append(archive.Files, *file)
+	}
+
+	metadata, err := json.MarshalIndent(mediaMetadata{
+		Note:         note,
+		Files:        archive.Files,
+		DownloadedAt: time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "序列化笔记元数据失败")
+	}
+	if err := os.WriteFile(archive.Metadata, metadata, 0644); err != nil {
+		return nil, errors.Wrap(err, "写入笔记元数据失败")
+	}
+
+	logrus.Infof("笔记 %s 的媒体已保存到 %s，共%d个文件", note.NoteID, dir, len(archive.Files))
+	return archive, nil
+}
+
+// noteImageURLs 返回笔记全部图片的最佳地址，跳过没有地址的图片
+func noteImageURLs(note FeedDetail) []string {
+	var urls []string
+	for _, img := range note.ImageList {
+		if url := img.BestURL(); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// downloadVideoStream 下载视频流，主地址失败时依次尝试备用地址
+func downloadVideoStream(ctx context.Context, d *downloader.ImageDownloader, stream *VideoStream) (*MediaFile, error) {
+	var lastErr error
+	for _, url := range append([]string{stream.MasterURL}, stream.BackupURLs...) {
+		path, size, err := d.DownloadFile(ctx, url, "video")
+		if err != nil {
+			logrus.Warnf("下载视频失败 %s: %v", url, err)
+			lastErr = err
+			continue
+		}
+		return &MediaFile{Type: "video", URL: url, Path: path, Size: size}, nil
+	}
+	return nil, errors.Wrap(lastErr, "下载视频失败")
+}
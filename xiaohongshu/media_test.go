@@ -0,0 +1,90 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/downloader"
+)
+
+func TestDownloadNoteMedia(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R'}
+	mp4 := []byte{0, 0, 0, 0x18, 'f', 't', 'y', 'p', 'm', 'p', '4', '2', 0, 0, 0, 0}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/img/", func(w http.ResponseWriter, r *http.Request) { w.Write(png) })
+	mux.HandleFunc("/video/backup", func(w http.ResponseWriter, r *http.Request) { w.Write(mp4) })
+	mux.HandleFunc("/video/master", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusForbidden)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	note := FeedDetail{
+		NoteID: "n1",
+		Title:  "春季养生茶",
+		ImageList: []DetailImageInfo{
+			{URLPre: server.URL + "/img/1-prv", InfoList: []ImageInfo{
+				{ImageScene: "WB_PRV", URL: server.URL + "/img/1-prv"},
+				{ImageScene: "WB_DFT", URL: server.URL + "/img/1-dft"},
+			}},
+			{URLDefault: server.URL + "/img/2"},
+			{},
+		},
+		Video: &Video{Media: &VideoMedia{Stream: VideoStreams{
+			H264: []VideoStream{{MasterURL: server.URL + "/video/master", BackupURLs: []string{server.URL + "/video/backup"}, Width: 1080, Height: 1920}},
+			H265: []VideoStream{{MasterURL: server.URL + "/video/small", Width: 720, Height: 1280}},
+		}}},
+	}
+
+	dir := filepath.Join(t.TempDir(), "n1")
+	archive, err := downloadNoteMedia(context.Background(), note, dir, downloader.URLPolicy{AllowPrivate: true})
+	require.NoError(t, err)
+
+	require.Len(t, archive.Files, 3)
+	assert.Equal(t, server.URL+"/img/1-dft", archive.Files[0].URL, "优先下载 WB_DFT 原图")
+	assert.Equal(t, filepath.Join(dir, "image_01.png"), archive.Files[0].Path)
+	assert.Equal(t, filepath.Join(dir, "image_02.png"), archive.Files[1].Path)
+	assert.Equal(t, "video", archive.Files[2].Type)
+	assert.Equal(t, server.URL+"/video/backup", archive.Files[2].URL, "主地址失败时使用备用地址")
+	assert.Equal(t, filepath.Join(dir, "video.mp4"), archive.Files[2].Path)
+	assert.Equal(t, int64(len(mp4)), archive.Files[2].Size)
+
+	data, err := os.ReadFile(archive.Metadata)
+	require.NoError(t, err)
+	var metadata mediaMetadata
+	require.NoError(t, json.Unmarshal(data, &metadata))
+	assert.Equal(t, "春季养生茶", metadata.Note.Title)
+	assert.Len(t, metadata.Files, 3)
+
+	_, err = downloadNoteMedia(context.Background(), FeedDetail{NoteID: "n2"}, filepath.Join(t.TempDir(), "n2"), downloader.URLPolicy{})
+	assert.ErrorIs(t, err, ErrNoMedia)
+}
+
+func TestVideoBestStream(t *testing.T) {
+	var none *Video
+	assert.Nil(t, none.BestStream())
+	assert.Nil(t, (&Video{}).BestStream())
+
+	video := &Video{Media: &VideoMedia{Stream: VideoStreams{
+		H264: []VideoStream{{MasterURL: "h264-720", Width: 720, Height: 1280}, {MasterURL: "h264-1080", Width: 1080, Height: 1920}},
+		H265: []VideoStream{{MasterURL: "h265-1080", Width: 1080, Height: 1920}, {Width: 2160, Height: 3840}},
+	}}}
+	assert.Equal(t, "h264-1080", video.BestStream().MasterURL, "清晰度相同时优先 H.264，跳过没有地址的流")
+}
+
+func TestDownloadNoteMediaRejectsInvalidFeedID(t *testing.T) {
+	action := &MediaDownloadAction{}
+
+	for _, feedID := range []string{"", "..", "../etc", "a/b"} {
+		_, err := action.DownloadNoteMedia(context.Background(), feedID, "token", t.TempDir())
+		assert.ErrorContains(t, err, "无效的笔记ID", feedID)
+	}
+}
@@ -0,0 +1,476 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sirupsen/logrus"
+)
+
+// apiKind 站点 JSON 接口类型
+type apiKind string
+
+const (
+	apiHomeFeed    apiKind = "/api/sns/web/v1/homefeed"
+	apiSearchNotes apiKind = "/api/sns/web/v1/search/notes"
+	apiNoteDetail  apiKind = "/api/sns/web/v1/feed"
+	apiComments    apiKind = "/api/sns/web/v2/comment/page"
+)
+
+var collectedAPIs = []apiKind{apiHomeFeed, apiSearchNotes, apiNoteDetail, apiComments}
+
+// matchAPIKind 根据请求地址判断接口类型，不需要采集的接口返回空字符串
+func matchAPIKind(rawURL string) apiKind {
+	path := rawURL
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+
+	for _, kind := range collectedAPIs {
+		if strings.HasSuffix(path, string(kind)) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// responseCollector 监听页面网络事件，收集站点 JSON 接口的响应体。
+// 服务端渲染的首屏数据不经过接口，仍需从 __INITIAL_STATE__ 读取；
+// 滚动加载的后续数据通过接口返回，由 collector 补全。
+type responseCollector struct {
+	mu        sync.Mutex
+	pending   map[proto.NetworkRequestID]apiKind
+	responses map[apiKind][][]byte
+
+	cancel context.CancelFunc
+}
+
+// startResponseCollector 开启网络监听，调用方需在结束时调用 Stop
+func startResponseCollector(page *rod.Page) *responseCollector {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	c := &responseCollector{
+		pending:   make(map[proto.NetworkRequestID]apiKind),
+		responses: make(map[apiKind][][]byte),
+		cancel:    cancel,
+	}
+
+	p := page.Context(ctx)
+	if err := (proto.NetworkEnable{}).Call(p); err != nil {
+		logrus.Debugf("开启网络监听失败: %v", err)
+	}
+
+	wait := p.EachEvent(func(e *proto.NetworkResponseReceived) {
+		if kind := matchAPIKind(e.Response.URL); kind != "" {
+			c.mu.Lock()
+			c.pending[e.RequestID] = kind
+			c.mu.Unlock()
+		}
+	}, func(e *proto.NetworkLoadingFinished) {
+		c.mu.Lock()
+		kind, ok := c.pending[e.RequestID]
+		delete(c.pending, e.RequestID)
+		c.mu.Unlock()
+		if !ok {
+			return
+		}
+
+		// 响应体需要在独立的 goroutine 中读取，避免阻塞事件循环
+		go func() {
+			body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(p)
+			if err != nil {
+				logrus.Debugf("读取接口响应失败 %s: %v", kind, err)
+				return
+			}
+
+			c.mu.Lock()
+			c.responses[kind] = append(c.responses[kind], []byte(body.Body))
+			c.mu.Unlock()
+		}()
+	})
+
+	go wait()
+
+	return c
+}
+
+// Stop 停止网络监听
+func (c *responseCollector) Stop() {
+	c.cancel()
+}
+
+func (c *responseCollector) bodies(kind apiKind) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([][]byte(nil), c.responses[kind]...)
+}
+
+// Feeds 返回已采集的首页或搜索接口中的笔记，按响应顺序排列
+func (c *responseCollector) Feeds(kind apiKind) []Feed {
+	var feeds []Feed
+	for _, body := range c.bodies(kind) {
+		items, err := parseAPIFeeds(body)
+		if err != nil {
+			logrus.Debugf("解析接口响应失败 %s: %v", kind, err)
+			continue
+		}
+		feeds = append(feeds, items...)
+	}
+	return feeds
+}
+
+// NoteDetail 返回已采集的详情接口中指定笔记的详情，未采集到时返回 nil
+func (c *responseCollector) NoteDetail(feedID string) *FeedDetail {
+	for _, body := range c.bodies(apiNoteDetail) {
+		details, err := parseAPINoteDetails(body)
+		if err != nil {
+			logrus.Debugf("解析详情接口响应失败: %v", err)
+			continue
+		}
+		for i := range details {
+			if details[i].NoteID == feedID {
+				return &details[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Comments 返回已采集的评论接口中的评论，以及最后一页的游标和是否还有更多
+func (c *responseCollector) Comments() CommentList {
+	var list CommentList
+	for _, body := range c.bodies(apiComments) {
+		page, err := parseAPIComments(body)
+		if err != nil {
+			logrus.Debugf("解析评论接口响应失败: %v", err)
+			continue
+		}
+		list.List = append(list.List, page.List...)
+		list.Cursor = page.Cursor
+		list.HasMore = page.HasMore
+	}
+	return list
+}
+
+// apiResponse 站点接口的通用响应结构
+type apiResponse[T any] struct {
+	Code    int    `json:"code"`
+	Success bool   `json:"success"`
+	Msg     string `json:"msg"`
+	Data    T      `json:"data"`
+}
+
+func decodeAPIResponse[T any](body []byte) (T, error) {
+	var resp apiResponse[T]
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return resp.Data, fmt.Errorf("failed to unmarshal api response: %w", err)
+	}
+
+	if !resp.Success && resp.Code != 0 {
+		return resp.Data, fmt.Errorf("api error %d: %s", resp.Code, resp.Msg)
+	}
+
+	return resp.Data, nil
+}
+
+// 接口返回的字段为蛇形命名，以下结构用于解析后转换为与 __INITIAL_STATE__ 一致的类型
+
+type apiUser struct {
+	UserID    string `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	NickName  string `json:"nick_name"`
+	Avatar    string `json:"avatar"`
+	Image     string `json:"image"`
+	XsecToken string `json:"xsec_token"`
+}
+
+func (u apiUser) toUser() User {
+	avatar := u.Avatar
+	if avatar == "" {
+		avatar = u.Image
+	}
+	return User{
+		UserID:    u.UserID,
+		Nickname:  u.Nickname,
+		NickName:  u.NickName,
+		Avatar:    avatar,
+		XsecToken: u.XsecToken,
+	}
+}
+
+type apiInteractInfo struct {
+	Liked          bool   `json:"liked"`
+	LikedCount     string `json:"liked_count"`
+	SharedCount    string `json:"shared_count"`
+	CommentCount   string `json:"comment_count"`
+	CollectedCount string `json:"collected_count"`
+	Collected      bool   `json:"collected"`
+}
+
+func (i apiInteractInfo) toInteractInfo() InteractInfo {
+	return InteractInfo{
+		Liked:          i.Liked,
+		LikedCount:     i.LikedCount,
+		SharedCount:    i.SharedCount,
+		CommentCount:   i.CommentCount,
+		CollectedCount: i.CollectedCount,
+		Collected:      i.Collected,
+	}
+}
+
+type apiImage struct {
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	URL        string `json:"url"`
+	URLPre     string `json:"url_pre"`
+	URLDefault string `json:"url_default"`
+	FileID     string `json:"file_id"`
+	InfoList   []struct {
+		ImageScene string `json:"image_scene"`
+		URL        string `json:"url"`
+	} `json:"info_list"`
+}
+
+func (img apiImage) toCover() Cover {
+	cover := Cover{
+		Width:      img.Width,
+		Height:     img.Height,
+		URL:        img.URL,
+		FileID:     img.FileID,
+		URLPre:     img.URLPre,
+		URLDefault: img.URLDefault,
+	}
+	for _, info := range img.InfoList {
+		cover.InfoList = append(cover.InfoList, ImageInfo{ImageScene: info.ImageScene, URL: info.URL})
+	}
+	return cover
+}
+
+type apiVideoStream struct {
+	MasterURL  string   `json:"master_url"`
+	BackupURLs []string `json:"backup_urls"`
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	Size       int64    `json:"size"`
+	Format     string   `json:"format"`
+}
+
+type apiVideo struct {
+	Capa struct {
+		Duration int `json:"duration"`
+	} `json:"capa"`
+	Media *struct {
+		Stream struct {
+			H264 []apiVideoStream `json:"h264"`
+			H265 []apiVideoStream `json:"h265"`
+		} `json:"stream"`
+	} `json:"media"`
+}
+
+func (v *apiVideo) toVideo() *Video {
+	if v == nil {
+		return nil
+	}
+
+	video := &Video{Capa: VideoCapability{Duration: v.Capa.Duration}}
+	if v.Media != nil {
+		video.Media = &VideoMedia{Stream: VideoStreams{
+			H264: toVideoStreams(v.Media.Stream.H264),
+			H265: toVideoStreams(v.Media.Stream.H265),
+		}}
+	}
+	return video
+}
+
+func toVideoStreams(streams []apiVideoStream) []VideoStream {
+	var result []VideoStream
+	for _, s := range streams {
+		result = append(result, VideoStream(s))
+	}
+	return result
+}
+
+type apiNoteCard struct {
+	Type         string          `json:"type"`
+	DisplayTitle string          `json:"display_title"`
+	User         apiUser         `json:"user"`
+	InteractInfo apiInteractInfo `json:"interact_info"`
+	Cover        apiImage        `json:"cover"`
+	Video        *apiVideo       `json:"video"`
+}
+
+type apiFeedItem struct {
+	ID        string      `json:"id"`
+	ModelType string      `json:"model_type"`
+	XsecToken string      `json:"xsec_token"`
+	NoteCard  apiNoteCard `json:"note_card"`
+}
+
+func (item apiFeedItem) toFeed() Feed {
+	feed := Feed{
+		ID:        item.ID,
+		ModelType: item.ModelType,
+		XsecToken: item.XsecToken,
+		NoteCard: NoteCard{
+			Type:         item.NoteCard.Type,
+			DisplayTitle: item.NoteCard.DisplayTitle,
+			User:         item.NoteCard.User.toUser(),
+			InteractInfo: item.NoteCard.InteractInfo.toInteractInfo(),
+			Cover:        item.NoteCard.Cover.toCover(),
+		},
+	}
+	feed.NoteCard.Video = item.NoteCard.Video.toVideo()
+	return feed
+}
+
+// parseAPIFeeds 解析首页或搜索接口响应中的笔记
+func parseAPIFeeds(body []byte) ([]Feed, error) {
+	data, err := decodeAPIResponse[struct {
+		Items []apiFeedItem `json:"items"`
+	}](body)
+	if err != nil {
+		return nil, err
+	}
+
+	feeds := make([]Feed, 0, len(data.Items))
+	for _, item := range data.Items {
+		feeds = append(feeds, item.toFeed())
+	}
+	return normalizeFeeds(feeds), nil
+}
+
+type apiNoteDetailCard struct {
+	NoteID       string          `json:"note_id"`
+	XsecToken    string          `json:"xsec_token"`
+	Title        string          `json:"title"`
+	Desc         string          `json:"desc"`
+	Type         string          `json:"type"`
+	Time         int64           `json:"time"`
+	LastUpdate   int64           `json:"last_update_time"`
+	IPLocation   string          `json:"ip_location"`
+	User         apiUser         `json:"user"`
+	InteractInfo apiInteractInfo `json:"interact_info"`
+	ImageList    []struct {
+		Width      int    `json:"width"`
+		Height     int    `json:"height"`
+		URLDefault string `json:"url_default"`
+		URLPre     string `json:"url_pre"`
+		LivePhoto  bool   `json:"live_photo"`
+		InfoList   []struct {
+			ImageScene string `json:"image_scene"`
+			URL        string `json:"url"`
+		} `json:"info_list"`
+	} `json:"image_list"`
+	TagList []Tag     `json:"tag_list"`
+	Video   *apiVideo `json:"video"`
+}
+
+func (n apiNoteDetailCard) toFeedDetail() FeedDetail {
+	detail := FeedDetail{
+		NoteID:       n.NoteID,
+		XsecToken:    n.XsecToken,
+		Title:        n.Title,
+		Desc:         n.Desc,
+		Type:         n.Type,
+		Time:         n.Time,
+		LastUpdate:   n.LastUpdate,
+		IPLocation:   n.IPLocation,
+		User:         n.User.toUser(),
+		InteractInfo: n.InteractInfo.toInteractInfo(),
+		TagList:      n.TagList,
+	}
+	for _, img := range n.ImageList {
+		info := DetailImageInfo{
+			Width:      img.Width,
+			Height:     img.Height,
+			URLDefault: img.URLDefault,
+			URLPre:     img.URLPre,
+			LivePhoto:  img.LivePhoto,
+		}
+		for _, i := range img.InfoList {
+			info.InfoList = append(info.InfoList, ImageInfo{ImageScene: i.ImageScene, URL: i.URL})
+		}
+		detail.ImageList = append(detail.ImageList, info)
+	}
+	detail.Video = n.Video.toVideo()
+	return detail
+}
+
+// parseAPINoteDetails 解析详情接口响应中的笔记，note_id 以外层 id 为准
+func parseAPINoteDetails(body []byte) ([]FeedDetail, error) {
+	data, err := decodeAPIResponse[struct {
+		Items []struct {
+			ID       string            `json:"id"`
+			NoteCard apiNoteDetailCard `json:"note_card"`
+		} `json:"items"`
+	}](body)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]FeedDetail, 0, len(data.Items))
+	for _, item := range data.Items {
+		detail := item.NoteCard.toFeedDetail()
+		if detail.NoteID == "" {
+			detail.NoteID = item.ID
+		}
+		details = append(details, detail)
+	}
+	return details, nil
+}
+
+type apiComment struct {
+	ID              string       `json:"id"`
+	NoteID          string       `json:"note_id"`
+	Content         string       `json:"content"`
+	LikeCount       string       `json:"like_count"`
+	Liked           bool         `json:"liked"`
+	CreateTime      int64        `json:"create_time"`
+	IPLocation      string       `json:"ip_location"`
+	UserInfo        apiUser      `json:"user_info"`
+	SubCommentCount string       `json:"sub_comment_count"`
+	SubComments     []apiComment `json:"sub_comments"`
+}
+
+func (c apiComment) toComment() Comment {
+	comment := Comment{
+		ID:              c.ID,
+		NoteID:          c.NoteID,
+		Content:         c.Content,
+		LikeCount:       c.LikeCount,
+		Liked:           c.Liked,
+		CreateTime:      c.CreateTime,
+		IPLocation:      c.IPLocation,
+		UserInfo:        c.UserInfo.toUser(),
+		SubCommentCount: c.SubCommentCount,
+	}
+	for _, sub := range c.SubComments {
+		comment.SubComments = append(comment.SubComments, sub.toComment())
+	}
+	return comment
+}
+
+// parseAPIComments 解析评论接口响应
+func parseAPIComments(body []byte) (CommentList, error) {
+	data, err := decodeAPIResponse[struct {
+		Comments []apiComment `json:"comments"`
+		Cursor   string       `json:"cursor"`
+		HasMore  bool         `json:"has_more"`
+	}](body)
+	if err != nil {
+		return CommentList{}, err
+	}
+
+	list := CommentList{Cursor: data.Cursor, HasMore: data.HasMore}
+	for _, c := range data.Comments {
+		comment := c.toComment()
+		comment.normalize()
+		list.List = append(list.List, comment)
+	}
+	return list, nil
+}
@@ -0,0 +1,94 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchAPIKind(t *testing.T) {
+	assert.Equal(t, apiHomeFeed, matchAPIKind("https://edith.xiaohongshu.com/api/sns/web/v1/homefeed"))
+	assert.Equal(t, apiSearchNotes, matchAPIKind("https://edith.xiaohongshu.com/api/sns/web/v1/search/notes"))
+	assert.Equal(t, apiNoteDetail, matchAPIKind("https://edith.xiaohongshu.com/api/sns/web/v1/feed"))
+	assert.Equal(t, apiComments, matchAPIKind("https://edith.xiaohongshu.com/api/sns/web/v2/comment/page?note_id=n1&cursor="))
+	assert.Equal(t, apiKind(""), matchAPIKind("https://edith.xiaohongshu.com/api/sns/web/v1/feed/other"))
+	assert.Equal(t, apiKind(""), matchAPIKind("https://www.xiaohongshu.com/explore"))
+}
+
+func TestParseAPIFeeds(t *testing.T) {
+	body := `{"code": 0, "success": true, "data": {"items": [
+		{"id": "n1", "model_type": "note", "xsec_token": "t1", "note_card": {
+			"type": "normal", "display_title": "春季养生茶",
+			"user": {"user_id": "u1", "nickname": "茶小白", "avatar": "https://a/1.jpg", "xsec_token": "ut1"},
+			"interact_info": {"liked": true, "liked_count": "1.2万"},
+			"cover": {"width": 1080, "height": 1440, "url_default": "https://c/1.jpg",
+				"info_list": [{"image_scene": "WB_DFT", "url": "https://c/1.jpg"}]}
+		}},
+		{"id": "n2", "model_type": "note", "xsec_token": "t2", "note_card": {
+			"type": "video", "display_title": "泡茶教程", "video": {"capa": {"duration": 63}}
+		}}
+	], "has_more": true}}`
+
+	feeds, err := parseAPIFeeds([]byte(body))
+	require.NoError(t, err)
+	require.Len(t, feeds, 2)
+
+	assert.Equal(t, "n1", feeds[0].ID)
+	assert.Equal(t, "t1", feeds[0].XsecToken)
+	assert.Equal(t, "春季养生茶", feeds[0].NoteCard.DisplayTitle)
+	assert.Equal(t, "茶小白", feeds[0].NoteCard.User.Nickname)
+	assert.Equal(t, "ut1", feeds[0].NoteCard.User.XsecToken)
+	assert.True(t, feeds[0].NoteCard.InteractInfo.Liked)
+	assert.Equal(t, "1.2万", feeds[0].NoteCard.InteractInfo.LikedCount)
+	assert.Equal(t, "https://c/1.jpg", feeds[0].NoteCard.Cover.URLDefault)
+	require.Len(t, feeds[0].NoteCard.Cover.InfoList, 1)
+	assert.Nil(t, feeds[0].NoteCard.Video)
+
+	require.NotNil(t, feeds[1].NoteCard.Video)
+	assert.Equal(t, 63, feeds[1].NoteCard.Video.Capa.Duration)
+
+	_, err = parseAPIFeeds([]byte(`{"code": 300011, "success": false, "msg": "账号异常"}`))
+	assert.Error(t, err)
+}
+
+func TestParseAPINoteDetails(t *testing.T) {
+	body := `{"code": 0, "success": true, "data": {"items": [{"id": "n1", "note_card": {
+		"title": "春季养生茶", "desc": "配方分享", "type": "normal", "time": 1700000000000,
+		"user": {"user_id": "u1", "nickname": "茶小白"},
+		"image_list": [{"width": 1080, "height": 1440, "url_default": "https://i/1.jpg", "live_photo": true}],
+		"tag_list": [{"id": "tag1", "name": "养生", "type": "topic"}]
+	}}]}}`
+
+	details, err := parseAPINoteDetails([]byte(body))
+	require.NoError(t, err)
+	require.Len(t, details, 1)
+
+	assert.Equal(t, "n1", details[0].NoteID)
+	assert.Equal(t, "配方分享", details[0].Desc)
+	assert.Equal(t, int64(1700000000000), details[0].Time)
+	require.Len(t, details[0].ImageList, 1)
+	assert.True(t, details[0].ImageList[0].LivePhoto)
+	require.Len(t, details[0].TagList, 1)
+	assert.Equal(t, "养生", details[0].TagList[0].Name)
+}
+
+func TestParseAPIComments(t *testing.T) {
+	body := `{"code": 0, "success": true, "data": {"cursor": "c2", "has_more": true, "comments": [
+		{"id": "c1", "note_id": "n1", "content": "好喝", "like_count": "3", "create_time": 1700000000000,
+			"user_info": {"user_id": "u2", "nickname": "路人", "image": "https://a/2.jpg"},
+			"sub_comment_count": "1",
+			"sub_comments": [{"id": "c1-1", "content": "同感", "user_info": {"user_id": "u3"}}]}
+	]}}`
+
+	list, err := parseAPIComments([]byte(body))
+	require.NoError(t, err)
+
+	assert.Equal(t, "c2", list.Cursor)
+	assert.True(t, list.HasMore)
+	require.Len(t, list.List, 1)
+	assert.Equal(t, "好喝", list.List[0].Content)
+	assert.Equal(t, "https://a/2.jpg", list.List[0].UserInfo.Avatar)
+	require.Len(t, list.List[0].SubComments, 1)
+	assert.Equal(t, "c1-1", list.List[0].SubComments[0].ID)
+}
@@ -0,0 +1,296 @@
+package xiaohongshu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/validator"
+)
+
+// NoteEdit 笔记修改内容，空字段表示保持不变
+type NoteEdit struct {
+	Title      string
+	Content    string
+	Tags       []string
+	ImagePaths []string // 非空时替换全部图片
+}
+
+// EditNote 在笔记管理列表中打开笔记编辑页，修改后重新发布
+func (a *NoteManagerAction) EditNote(ctx context.Context, noteID string, edit NoteEdit) error {
+	page := a.page.Context(ctx)
+
+	if err := a.openNoteEditor(page, noteID); err != nil {
+		return err
+	}
+
+	if len(edit.ImagePaths) > 0 {
+		if err := removeUploadedImages(page); err != nil {
+			return errors.Wrap(err, "删除原有图片失败")
+		}
+		if _, err := uploadImages(page, edit.ImagePaths); err != nil {
+			return errors.Wrap(err, "上传新图片失败")
+		}
+	}
+
+	if edit.Title != "" {
+		titleElem, err := page.Element("div.d-input input")
+		if err != nil {
+			return errors.Wrap(err, "没有找到标题输入框")
+		}
+		if err := titleElem.SelectAllText(); err != nil {
+			return errors.Wrap(err, "选中原标题失败")
+		}
+		if err := titleElem.Input(edit.Title); err != nil {
+			return errors.Wrap(err, "输入新标题失败")
+		}
+	}
+
+	editor, ok := getContentElement(page)
+	if !ok {
+		return errors.New("没有找到内容输入框")
+	}
+
+	if edit.Content != "" {
+		if err := replaceEditorContent(page, editor, edit.Content); err != nil {
+			return errors.Wrap(err, "替换正文失败")
+		}
+	}
+
+	if len(edit.Tags) > 0 {
+		if err := inputTags(page, editor, edit.Tags); err != nil {
+			return errors.Wrap(err, "添加标签失败")
+		}
+	}
+
+	confirmed, err := submitPublish(page)
+	if err != nil {
+		return errors.Wrap(err, "提交修改失败")
+	}
+	if !confirmed {
+		logrus.Warnf("已提交修改，但未看到保存成功提示，将重新读取笔记确认: %s", noteID)
+	}
+
+	if err := a.verifyEdit(page, noteID, edit); err != nil {
+		return err
+	}
+
+	logrus.Infof("笔记已修改: %s", noteID)
+	return nil
+}
+
+// verifyEdit 保存后重新打开编辑页读取标题和正文，确认修改已生效
+func (a *NoteManagerAction) verifyEdit(page *rod.Page, noteID string, edit NoteEdit) error {
+	if edit.Title == "" && edit.Content == "" {
+		return nil
+	}
+
+	if err := a.openNoteEditor(page, noteID); err != nil {
+		return errors.Wrap(err, "重新打开笔记确认修改失败")
+	}
+
+	form, err := readFormSnapshot(page)
+	if err != nil {
+		return errors.Wrap(err, "读取修改后的笔记失败")
+	}
+
+	if mismatches := compareEdit(form, edit); len(mismatches) > 0 {
+		return errors.Errorf("修改后的笔记与请求不一致: %s", strings.Join(mismatches, "；"))
+	}
+
+	return nil
+}
+
+// compareEdit 比较编辑页表单与修改内容，返回不一致项，未修改的字段不参与比较
+func compareEdit(form *FormSnapshot, edit NoteEdit) []string {
+	var mismatches []string
+
+	if title := strings.TrimSpace(edit.Title); title != "" && form.Title != title {
+		mismatches = append(mismatches, fmt.Sprintf("标题为 %q，请求为 %q", form.Title, title))
+	}
+
+	// 正文末尾可能带有话题，只检查请求正文是否完整出现
+	if want := strings.Join(strings.Fields(edit.Content), " "); want != "" && !strings.Contains(strings.Join(strings.Fields(form.Content), " "), want) {
+		mismatches = append(mismatches, "正文与请求不一致")
+	}
+
+	return mismatches
+}
+
+// DeleteNote 在笔记管理列表中删除笔记
+func (a *NoteManagerAction) DeleteNote(ctx context.Context, noteID string) error {
+	page := a.page.Context(ctx)
+
+	card, err := a.openNoteCard(page, noteID)
+	if err != nil {
+		return err
+	}
+
+	if err := clickCardAction(card, "删除"); err != nil {
+		return err
+	}
+
+	confirm, err := page.Timeout(5*time.Second).ElementR("div.d-modal button, div.d-popconfirm button", "确认|确定|删除")
+	if err != nil {
+		return errors.Wrap(err, "未找到删除确认按钮")
+	}
+
+	if err := confirm.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "确认删除失败")
+	}
+
+	// 删除成功后卡片会从列表中移除，重新扫描列表确认该笔记已不存在
+	if err := waitUntil(page.GetContext(), 10*time.Second, "笔记从列表中移除", func() bool {
+		found := false
+		if err := scanNotes(page, func(_ *rod.Element, note *ManagedNote) bool {
+			found = note.NoteID == noteID
+			return found
+		}); err != nil {
+			logrus.Debugf("扫描笔记管理列表失败: %v", err)
+			return false
+		}
+		return !found
+	}); err != nil {
+		return errors.Wrapf(err, "删除笔记后列表中仍存在该笔记: %s", noteID)
+	}
+
+	logrus.Infof("笔记已删除: %s", noteID)
+	return nil
+}
+
+// openNoteCard 打开笔记管理页并定位笔记卡片，不在已加载的列表中时滚动加载更多
+func (a *NoteManagerAction) openNoteCard(page *rod.Page, noteID string) (*rod.Element, error) {
+	if err := openNoteManager(page); err != nil {
+		return nil, err
+	}
+
+	var card *rod.Element
+	err := scanNotes(page, func(elem *rod.Element, note *ManagedNote) bool {
+		if note.NoteID == noteID {
+			card = elem
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, ErrNoteNotFound
+	}
+
+	return card, nil
+}
+
+// openNoteEditor 打开笔记的编辑页并等待标题输入框出现
+func (a *NoteManagerAction) openNoteEditor(page *rod.Page, noteID string) error {
+	card, err := a.openNoteCard(page, noteID)
+	if err != nil {
+		return err
+	}
+
+	if err := clickCardAction(card, "编辑"); err != nil {
+		return err
+	}
+
+	// 编辑页与发布页结构相同，等待标题输入框出现
+	if _, err := page.Timeout(30 * time.Second).Element("div.d-input input"); err != nil {
+		return errors.Wrap(err, "打开笔记编辑页失败")
+	}
+
+	return nil
+}
+
+// clickCardAction 点击笔记卡片上的操作按钮（编辑、删除等）
+func clickCardAction(card *rod.Element, label string) error {
+	if err := card.ScrollIntoView(); err != nil {
+		logrus.Debugf("滚动到笔记卡片失败: %v", err)
+	}
+
+	// 操作按钮在鼠标悬停时才显示
+	if err := card.Hover(); err != nil {
+		logrus.Debugf("悬停笔记卡片失败: %v", err)
+	}
+
+	button, err := card.ElementR("span, div.control, button", label)
+	if err != nil {
+		return errors.Wrapf(err, "未找到%s按钮", label)
+	}
+
+	if err := button.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrapf(err, "点击%s按钮失败", label)
+	}
+
+	return nil
+}
+
+// removeUploadedImages 删除编辑页中已有的全部图片，每次删除后等待图片数量减少
+func removeUploadedImages(page *rod.Page) error {
+	for i := 0; i < validator.MaxImages; i++ {
+		count, err := thumbnailCount(page)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+
+		thumb, err := page.Element(selectorUploadedImage)
+		if err != nil {
+			return errors.Wrap(err, "未找到已上传图片")
+		}
+
+		if err := thumb.Hover(); err != nil {
+			logrus.Debugf("悬停图片失败: %v", err)
+		}
+
+		closeBtn, err := thumb.Element(".close, .delete, .img-delete")
+		if err != nil {
+			return errors.Wrap(err, "未找到图片删除按钮")
+		}
+
+		if err := closeBtn.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return errors.Wrap(err, "点击图片删除按钮失败")
+		}
+
+		if err := waitUntil(page.GetContext(), 5*time.Second, "图片删除", func() bool {
+			remaining, err := thumbnailCount(page)
+			return err == nil && remaining < count
+		}); err != nil {
+			return err
+		}
+	}
+
+	count, err := thumbnailCount(page)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Errorf("删除图片后仍剩余%d张图片", count)
+	}
+
+	return nil
+}
+
+// replaceEditorContent 清空正文编辑器并输入新内容
+func replaceEditorContent(page *rod.Page, editor *rod.Element, content string) error {
+	if err := editor.Focus(); err != nil {
+		return err
+	}
+
+	if err := page.KeyActions().Press(input.ControlLeft).Type('a').Release(input.ControlLeft).Do(); err != nil {
+		return err
+	}
+
+	if err := page.Keyboard.Type(input.Backspace); err != nil {
+		return err
+	}
+
+	return page.InsertText(content)
+}
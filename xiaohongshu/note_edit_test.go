@@ -0,0 +1,22 @@
+package xiaohongshu
+
+import "testing"
+
+func TestCompareEdit(t *testing.T) {
+	form := &FormSnapshot{
+		Title:   "新标题",
+		Content: "新的正文\n第二行 #话题[话题]#",
+	}
+
+	if mismatches := compareEdit(form, NoteEdit{Title: "新标题", Content: "新的正文 第二行"}); len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %v", mismatches)
+	}
+
+	if mismatches := compareEdit(form, NoteEdit{Tags: []string{"话题"}}); len(mismatches) != 0 {
+		t.Errorf("expected unchanged fields to be skipped, got %v", mismatches)
+	}
+
+	if mismatches := compareEdit(form, NoteEdit{Title: "旧标题", Content: "旧的正文"}); len(mismatches) != 2 {
+		t.Errorf("expected 2 mismatches, got %v", mismatches)
+	}
+}
@@ -0,0 +1,325 @@
+package xiaohongshu
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	urlOfNoteManager = `https://creator.xiaohongshu.com/new/note-manager`
+
+	// 笔记管理列表中的单条笔记
+	selectorManagedNote = `div.note`
+
+	// maxNoteScrolls 查找笔记时最多滚动加载的次数
+	maxNoteScrolls = 50
+	// noteScrollAttempts 每次滚动后没有新笔记时的重试次数，用尽后视为列表到底
+	noteScrollAttempts = 3
+	// noteScrollTimeout 每次滚动后等待新笔记加载的最长时间
+	noteScrollTimeout = 5 * time.Second
+)
+
+// NoteStatus 笔记审核状态
+type NoteStatus string
+
+const (
+	NoteStatusPublished NoteStatus = "published" // 已发布
+	NoteStatusReviewing NoteStatus = "reviewing" // 审核中
+	NoteStatusRejected  NoteStatus = "rejected"  // 未通过
+	NoteStatusUnknown   NoteStatus = "unknown"
+)
+
+// ManagedNote 创作者中心笔记管理列表中的笔记
+type ManagedNote struct {
+	NoteID      string     `json:"note_id"`
+	Title       string     `json:"title"`
+	Status      NoteStatus `json:"status"`
+	Reason      string     `json:"reason,omitempty"` // 未通过原因
+	PublishTime string     `json:"publish_time,omitempty"`
+}
+
+// ErrNoteNotFound 笔记管理列表中未找到笔记
+var ErrNoteNotFound = errors.New("笔记管理列表中未找到该笔记")
+
+var noteIDPattern = regexp.MustCompile(`"noteId"\s*:\s*"([0-9a-zA-Z]+)"|/explore/([0-9a-zA-Z]+)|noteId=([0-9a-zA-Z]+)`)
+
+type NoteManagerAction struct {
+	page *rod.Page
+}
+
+func NewNoteManagerAction(page *rod.Page) *NoteManagerAction {
+	pp := page.Timeout(60 * time.Second)
+
+	return &NoteManagerAction{page: pp}
+}
+
+// ListNotes 读取笔记管理列表第一页的笔记
+func (a *NoteManagerAction) ListNotes(ctx context.Context) ([]ManagedNote, error) {
+	page := a.page.Context(ctx)
+
+	if err := openNoteManager(page); err != nil {
+		return nil, err
+	}
+
+	elems, err := page.Elements(selectorManagedNote)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取笔记管理列表失败")
+	}
+
+	notes := make([]ManagedNote, 0, len(elems))
+	for _, elem := range elems {
+		note, err := parseManagedNote(elem)
+		if err != nil {
+			logrus.Debugf("解析笔记失败: %v", err)
+			continue
+		}
+		notes = append(notes, *note)
+	}
+
+	return notes, nil
+}
+
+// FindNote 在笔记管理列表中查找第一条满足 match 的笔记，当前已加载的笔记中没有时滚动加载更多，
+// 直到找到或列表到底
+func (a *NoteManagerAction) FindNote(ctx context.Context, match func(note *ManagedNote) bool) (*ManagedNote, error) {
+	page := a.page.Context(ctx)
+
+	if err := openNoteManager(page); err != nil {
+		return nil, err
+	}
+
+	var found *ManagedNote
+	err := scanNotes(page, func(_ *rod.Element, note *ManagedNote) bool {
+		if match(note) {
+			found = note
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrNoteNotFound
+	}
+
+	return found, nil
+}
+
+// GetNoteStatus 按笔记 ID 查询审核状态
+func (a *NoteManagerAction) GetNoteStatus(ctx context.Context, noteID string) (*ManagedNote, error) {
+	return a.FindNote(ctx, func(note *ManagedNote) bool {
+		return note.NoteID == noteID
+	})
+}
+
+// SnapshotNoteIDs 记录笔记管理列表第一页已有的笔记 ID，发布前调用，
+// 之后 WaitForNote 只匹配不在其中的新笔记
+func (a *NoteManagerAction) SnapshotNoteIDs(ctx context.Context) (map[string]bool, error) {
+	notes, err := a.ListNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		if note.NoteID != "" {
+			known[note.NoteID] = true
+		}
+	}
+
+	return known, nil
+}
+
+// WaitForNote 发布后轮询笔记管理列表，直到出现标题匹配且不在 known 中的新笔记或超时
+func (a *NoteManagerAction) WaitForNote(ctx context.Context, title string, known map[string]bool, timeout time.Duration) (*ManagedNote, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		notes, err := a.ListNotes(ctx)
+		if err != nil {
+			logrus.Debugf("读取笔记管理列表失败: %v", err)
+		}
+
+		if note := matchNewNote(notes, title, known); note != nil {
+			return note, nil
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrNoteNotFound
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(3 * time.Second):
+		}
+	}
+}
+
+// matchNewNote 返回第一条标题匹配且 ID 不在 known 中的笔记。
+// 无法读取 ID 的笔记不能与发布前的笔记区分，不参与匹配
+func matchNewNote(notes []ManagedNote, title string, known map[string]bool) *ManagedNote {
+	title = strings.TrimSpace(title)
+
+	for i := range notes {
+		if notes[i].NoteID == "" || known[notes[i].NoteID] {
+			continue
+		}
+		if notes[i].Title == title {
+			return &notes[i]
+		}
+	}
+
+	return nil
+}
+
+// openNoteManager 打开笔记管理页面并等待列表加载
+func openNoteManager(page *rod.Page) error {
+	if err := page.Navigate(urlOfNoteManager); err != nil {
+		return errors.Wrap(err, "打开笔记管理页面失败")
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return errors.Wrap(err, "等待笔记管理页面加载失败")
+	}
+
+	if _, err := page.Timeout(15 * time.Second).Element(selectorManagedNote); err != nil {
+		return errors.Wrap(err, "笔记管理列表为空或未加载，可能需要重新登录")
+	}
+
+	return nil
+}
+
+// scanNotes 依次解析笔记管理列表中的笔记，visit 返回 true 时停止。
+// 已加载的笔记读完后滚动加载下一页，连续滚动仍没有新笔记时视为列表到底
+func scanNotes(page *rod.Page, visit func(elem *rod.Element, note *ManagedNote) bool) error {
+	scanned := 0
+
+	for scrolls := 0; ; scrolls++ {
+		elems, err := page.Elements(selectorManagedNote)
+		if err != nil {
+			return errors.Wrap(err, "读取笔记管理列表失败")
+		}
+
+		for _, elem := range elems[min(scanned, len(elems)):] {
+			note, err := parseManagedNote(elem)
+			if err != nil {
+				logrus.Debugf("解析笔记失败: %v", err)
+				continue
+			}
+			if visit(elem, note) {
+				return nil
+			}
+		}
+		scanned = len(elems)
+
+		if scrolls >= maxNoteScrolls || !loadMoreNotes(page, scanned) {
+			logrus.Debugf("笔记管理列表已读完，共%d篇笔记", scanned)
+			return nil
+		}
+	}
+}
+
+// loadMoreNotes 滚动到最后一条笔记，等待列表数量超过 loaded，失败时重试
+func loadMoreNotes(page *rod.Page, loaded int) bool {
+	for attempt := 0; attempt < noteScrollAttempts; attempt++ {
+		if _, err := page.Eval(`(selector) => {
+			const notes = document.querySelectorAll(selector);
+			if (notes.length > 0) notes[notes.length - 1].scrollIntoView();
+			window.scrollTo(0, document.body.scrollHeight);
+		}`, selectorManagedNote); err != nil {
+			logrus.Debugf("滚动笔记管理列表失败: %v", err)
+			return false
+		}
+
+		if err := waitUntil(page.GetContext(), noteScrollTimeout, "笔记管理列表加载", func() bool {
+			elems, err := page.Elements(selectorManagedNote)
+			return err == nil && len(elems) > loaded
+		}); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseManagedNote(elem *rod.Element) (*ManagedNote, error) {
+	html, err := elem.HTML()
+	if err != nil {
+		return nil, err
+	}
+
+	note := &ManagedNote{
+		NoteID: extractNoteID(html),
+		Title:  elementText(elem, ".title, .raw"),
+	}
+
+	if note.Title == "" {
+		return nil, errors.New("笔记标题为空")
+	}
+
+	statusText := elementText(elem, ".d-tag, .status, .note-status")
+	note.Status = classifyNoteStatus(statusText)
+	if note.Status == NoteStatusRejected {
+		note.Reason = elementText(elem, ".reason, .fail-reason, .d-tooltip-content")
+	}
+	note.PublishTime = elementText(elem, ".time")
+
+	return note, nil
+}
+
+// elementText 读取子元素文本，子元素不存在时立即返回空字符串
+func elementText(elem *rod.Element, selector string) string {
+	has, child, err := elem.Has(selector)
+	if err != nil || !has {
+		return ""
+	}
+
+	text, err := child.Text()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// extractNoteID 从笔记卡片 HTML 中提取笔记 ID（埋点数据或链接）
+func extractNoteID(html string) string {
+	match := noteIDPattern.FindStringSubmatch(html)
+	if match == nil {
+		return ""
+	}
+
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// classifyNoteStatus 根据状态标签文案判断审核状态，无标签或无法识别的文案视为未知
+func classifyNoteStatus(text string) NoteStatus {
+	text = strings.TrimSpace(text)
+
+	switch {
+	case strings.Contains(text, "审核中"), strings.Contains(text, "发布中"):
+		return NoteStatusReviewing
+	case strings.Contains(text, "未通过"), strings.Contains(text, "违规"), strings.Contains(text, "失败"):
+		return NoteStatusRejected
+	case strings.Contains(text, "已发布"), strings.Contains(text, "仅自己可见"), strings.Contains(text, "好友可见"):
+		return NoteStatusPublished
+	default:
+		return NoteStatusUnknown
+	}
+}
@@ -0,0 +1,58 @@
+package xiaohongshu
+
+import "testing"
+
+func TestClassifyNoteStatus(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected NoteStatus
+	}{
+		{"", NoteStatusUnknown},
+		{"已发布", NoteStatusPublished},
+		{"审核中", NoteStatusReviewing},
+		{"审核未通过", NoteStatusRejected},
+		{"内容违规", NoteStatusRejected},
+		{"草稿", NoteStatusUnknown},
+	}
+
+	for _, test := range tests {
+		if got := classifyNoteStatus(test.input); got != test.expected {
+			t.Errorf("classifyNoteStatus(%q) = %q, expected %q", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestExtractNoteID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<div data-impression='{"noteTarget":{"type":"NoteTarget","value":{"noteId":"6650a1b2c3d4e5f601234567"}}}'>`, "6650a1b2c3d4e5f601234567"},
+		{`<a href="https://www.xiaohongshu.com/explore/6650a1b2c3d4e5f601234567">`, "6650a1b2c3d4e5f601234567"},
+		{`<div class="note"><span class="title">无链接</span></div>`, ""},
+	}
+
+	for _, test := range tests {
+		if got := extractNoteID(test.input); got != test.expected {
+			t.Errorf("extractNoteID(%q) = %q, expected %q", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestMatchNewNote(t *testing.T) {
+	notes := []ManagedNote{
+		{NoteID: "", Title: "标题"},
+		{NoteID: "new", Title: "其他"},
+		{NoteID: "old", Title: "标题"},
+		{NoteID: "new2", Title: "标题"},
+	}
+
+	note := matchNewNote(notes, " 标题 ", map[string]bool{"old": true})
+	if note == nil || note.NoteID != "new2" {
+		t.Fatalf("matchNewNote() = %+v, expected new2", note)
+	}
+
+	if note := matchNewNote(notes[:3], "标题", map[string]bool{"old": true}); note != nil {
+		t.Errorf("matchNewNote() = %+v, expected nil", note)
+	}
+}
@@ -0,0 +1,200 @@
+package xiaohongshu
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// maxProductPages 商品列表最多翻页次数，防止页面异常时无限翻页
+const maxProductPages = 50
+
+// Product 商品选择弹窗中的商品
+type Product struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Price string `json:"price,omitempty"` // 页面展示的价格，如 "39.9" 或 "39.9-59.9"
+	Stock int    `json:"stock"`           // 库存，页面未展示时为 -1
+}
+
+var (
+	productPricePattern = regexp.MustCompile(`[¥￥]\s*([0-9]+(?:\.[0-9]+)?(?:\s*[-~]\s*[0-9]+(?:\.[0-9]+)?)?)`)
+	productStockPattern = regexp.MustCompile(`库存[:：]?\s*([0-9]+)`)
+)
+
+type ProductListAction struct {
+	page *rod.Page
+}
+
+// NewProductListAction 打开发布页，商品列表通过发布页的商品选择弹窗读取
+func NewProductListAction(page *rod.Page) (*ProductListAction, error) {
+	action, err := NewPublishImageAction(page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProductListAction{page: action.page}, nil
+}
+
+// ListProducts 打开商品选择弹窗，翻页读取全部商品后关闭弹窗
+func (a *ProductListAction) ListProducts(ctx context.Context) ([]Product, error) {
+	page := a.page.Context(ctx)
+
+	addButton, err := findAddProductButton(page.Timeout(10 * time.Second))
+	if err != nil {
+		return nil, errors.Wrap(err, "未找到添加商品入口，当前账号可能未开通商品功能")
+	}
+
+	if err := addButton.ScrollIntoView(); err != nil {
+		logrus.Debugf("滚动到添加商品按钮失败: %v", err)
+	}
+
+	if err := addButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return nil, errors.Wrap(err, "点击添加商品按钮失败")
+	}
+
+	modal, err := page.Timeout(15 * time.Second).Element("div.multi-goods-selector-modal")
+	if err != nil {
+		return nil, errors.Wrap(err, "打开商品选择弹窗失败")
+	}
+
+	if err := waitForProductListLoad(modal); err != nil {
+		return nil, err
+	}
+
+	var products []Product
+	seen := make(map[string]bool)
+
+	for pageNum := 0; pageNum < maxProductPages; pageNum++ {
+		added := 0
+		for _, product := range readProductList(modal) {
+			key := product.ID
+			if key == "" {
+				key = product.Name
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			products = append(products, product)
+			added++
+		}
+
+		// 翻页或滚动加载后没有新商品，说明已读取完毕
+		if added == 0 || !loadMoreProducts(modal, len(seen)) {
+			break
+		}
+	}
+
+	closeProductModal(page, modal)
+
+	logrus.Infof("读取到%d个商品", len(products))
+	return products, nil
+}
+
+// readProductList 读取弹窗中当前展示的全部商品
+func readProductList(modal *rod.Element) []Product {
+	cards, err := modal.Elements(".good-card-container")
+	if err != nil {
+		return nil
+	}
+
+	products := make([]Product, 0, len(cards))
+	for _, card := range cards {
+		text, err := card.Text()
+		if err != nil {
+			continue
+		}
+
+		product := parseProductText(text)
+		if name := elementText(card, ".sku-name"); name != "" {
+			product.Name = name
+		}
+		if product.Name == "" && product.ID == "" {
+			continue
+		}
+
+		products = append(products, product)
+	}
+
+	return products
+}
+
+// parseProductText 从商品卡片文本中解析商品ID、价格与库存，名称取第一行
+func parseProductText(text string) Product {
+	product := Product{Stock: -1}
+
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			product.Name = line
+			break
+		}
+	}
+
+	if match := productIDPattern.FindStringSubmatch(text); match != nil {
+		product.ID = match[1]
+	}
+
+	if match := productPricePattern.FindStringSubmatch(text); match != nil {
+		product.Price = strings.Join(strings.Fields(match[1]), "")
+	}
+
+	if match := productStockPattern.FindStringSubmatch(text); match != nil {
+		if stock, err := strconv.Atoi(match[1]); err == nil {
+			product.Stock = stock
+		}
+	}
+
+	return product
+}
+
+// loadMoreProducts 点击下一页，没有分页器时滚动列表触发加载，返回是否加载出新内容
+func loadMoreProducts(modal *rod.Element, loaded int) bool {
+	if has, next, err := modal.Has(".d-pagination .next:not(.disabled), .d-pagination-next:not(.disabled)"); err == nil && has {
+		firstCard := elementText(modal, ".good-card-container")
+		if err := next.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			logrus.Debugf("点击商品列表下一页失败: %v", err)
+			return false
+		}
+
+		return waitUntil(modal.GetContext(), 10*time.Second, "商品列表翻页", func() bool {
+			current := elementText(modal, ".good-card-container")
+			return current != "" && current != firstCard
+		}) == nil
+	}
+
+	if _, err := modal.Eval(`() => {
+		const list = this.querySelector('.goods-list, .d-modal-content') || this;
+		list.scrollTop = list.scrollHeight;
+		const cards = this.querySelectorAll('.good-card-container');
+		if (cards.length) cards[cards.length - 1].scrollIntoView();
+	}`); err != nil {
+		logrus.Debugf("滚动商品列表失败: %v", err)
+		return false
+	}
+
+	return waitUntil(modal.GetContext(), 3*time.Second, "商品列表加载", func() bool {
+		cards, err := modal.Elements(".good-card-container")
+		return err == nil && len(cards) > loaded
+	}) == nil
+}
+
+// closeProductModal 取消并关闭商品选择弹窗，不保存任何选择
+func closeProductModal(page *rod.Page, modal *rod.Element) {
+	if has, cancel, err := modal.Has("div.d-modal-footer button:not(.primary), .d-modal-close"); err == nil && has {
+		if err := cancel.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			logrus.Debugf("关闭商品选择弹窗失败: %v", err)
+		}
+	}
+
+	if err := waitForModalClose(page); err != nil {
+		logrus.Debugf("%v", err)
+	}
+}
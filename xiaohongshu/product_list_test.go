@@ -0,0 +1,23 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProductText(t *testing.T) {
+	product := parseProductText("正宗特级湘西莓茶\nID：64f1a2b3c4d5e6f7a8b9c0d1\n¥39.9\n库存 120")
+	assert.Equal(t, Product{
+		ID:    "64f1a2b3c4d5e6f7a8b9c0d1",
+		Name:  "正宗特级湘西莓茶",
+		Price: "39.9",
+		Stock: 120,
+	}, product)
+
+	product = parseProductText("儿时麦芽糖\n￥ 12.5 - 25\n")
+	assert.Equal(t, "儿时麦芽糖", product.Name)
+	assert.Equal(t, "12.5-25", product.Price)
+	assert.Equal(t, -1, product.Stock)
+	assert.Empty(t, product.ID)
+}
@@ -2,7 +2,10 @@ package xiaohongshu
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +21,51 @@ type PublishImageContent struct {
 	Tags       []string
 	Products   []string
 	ImagePaths []string
+
+	ProductIDs    []string             // 按商品ID添加的商品
+	ProductMatch  ProductMatchMode     // 商品名称匹配方式，默认 contains
+	ProductPolicy ProductFailurePolicy // 商品添加失败时的处理策略，默认 fail
+
+	CoverIndex int // 封面图片在 ImagePaths 中的下标，默认第一张
+
+	Visibility Visibility // 可见范围，为空时公开
+	Location   string     // 地点搜索关键词
+	Mentions   []string   // 需要@的用户昵称
+	IsOriginal bool       // 是否声明原创
+
+	DryRun bool // 仅填写表单不提交，用于端到端验证
+
+	// BeforeSubmit 点击发布按钮前调用，返回错误时不发布。
+	// 点击之后的失败无法确认笔记是否已发布，调用方可据此区分失败发生在点击前还是点击后。
+	BeforeSubmit func() error
+}
+
+// PublishResult 发布结果
+type PublishResult struct {
+	DryRun       bool              `json:"dry_run"`
+	Form         *FormSnapshot     `json:"form,omitempty"`       // dry-run 时从页面读回的表单内容
+	Screenshot   []byte            `json:"screenshot,omitempty"` // dry-run 时填写完成后的页面截图（PNG）
+	ImageUploads []ImageUpload     `json:"image_uploads,omitempty"`
+	Products     []SelectedProduct `json:"products,omitempty"`         // 实际选中的商品
+	Skipped      []SkippedProduct  `json:"skipped_products,omitempty"` // 按失败处理策略跳过的商品
+
+	// Unconfirmed 已点击发布，但在等待时间内没有看到发布成功提示，笔记可能已经发布
+	Unconfirmed bool `json:"unconfirmed,omitempty"`
+}
+
+// FormSnapshot 发布表单当前填写的内容
+type FormSnapshot struct {
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	ImageCount int      `json:"image_count"`
+	Products   []string `json:"products,omitempty"`   // 已添加的商品名称
+	Visibility string   `json:"visibility,omitempty"` // 可见范围：public、private、friends，无法识别时为页面文案
+	Location   string   `json:"location,omitempty"`
+	Mentions   []string `json:"mentions,omitempty"` // 正文中@的用户昵称
+	IsOriginal bool     `json:"is_original"`
+
+	// Mismatches 表单内容与请求不一致的字段说明，为空表示全部一致
+	Mismatches []string `json:"mismatches,omitempty"`
 }
 
 type PublishAction struct {
@@ -26,13 +74,23 @@ type PublishAction struct {
 
 const (
 	urlOfPublic = `https://creator.xiaohongshu.com/publish/publish?source=official`
+
+	// 已上传图片的缩略图
+	selectorUploadedImage = `.img-preview-area .pr`
+
+	// inputSettleTimeout 输入后等待内容写入输入框的最长时间
+	inputSettleTimeout = 5 * time.Second
+	// submitTimeout 点击发布后等待发布结果的最长时间
+	submitTimeout = 30 * time.Second
 )
 
 func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 
 	pp := page.Timeout(120 * time.Second) // 增加超时时间到120秒
 
-	pp.MustNavigate(urlOfPublic)
+	if err := pp.Navigate(urlOfPublic); err != nil {
+		return nil, errors.Wrap(err, "打开发布页面失败")
+	}
 
 	// 使用更灵活的元素查找方式
 	uploadContent, err := pp.Element(`div.upload-content`)
@@ -46,10 +104,15 @@ func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 
 	slog.Info("wait for upload-content visible success")
 
-	// 等待一段时间确保页面完全加载
-	time.Sleep(2 * time.Second)
+	// 等待创作类型切换标签渲染完成
+	if _, err := pp.Timeout(15 * time.Second).Element("div.creator-tab"); err != nil {
+		return nil, errors.Wrap(err, "找不到创作类型标签，页面可能未完全加载")
+	}
 
-	createElems := pp.MustElements("div.creator-tab")
+	createElems, err := pp.Elements("div.creator-tab")
+	if err != nil {
+		return nil, errors.Wrap(err, "读取创作类型标签失败")
+	}
 	slog.Info("foundcreator-tab elements", "count", len(createElems))
 	for _, elem := range createElems {
 		text, err := elem.Text()
@@ -67,77 +130,340 @@ func NewPublishImageAction(page *rod.Page) (*PublishAction, error) {
 		}
 	}
 
-	time.Sleep(2 * time.Second)
+	// 切换到图文标签后，等待图片上传输入框出现
+	if _, err := pp.Timeout(15 * time.Second).Element(selectorImageInput); err != nil {
+		return nil, errors.Wrap(err, "切换到上传图文失败")
+	}
 
 	return &PublishAction{
 		page: pp,
 	}, nil
 }
 
-func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent) error {
+func (p *PublishAction) Publish(ctx context.Context, content PublishImageContent) (*PublishResult, error) {
 	page := p.page.Context(ctx)
 
+	result := &PublishResult{}
+
 	// 如果有图片，先上传图片
 	if len(content.ImagePaths) > 0 {
-		if err := uploadImages(page, content.ImagePaths); err != nil {
-			return errors.Wrap(err, "小红书上传图片失败")
+		uploads, err := uploadImages(page, orderImagesForCover(content.ImagePaths, content.CoverIndex))
+		if err != nil {
+			return nil, errors.Wrap(err, "小红书上传图片失败")
 		}
+		result.ImageUploads = uploads
 	}
 
 	// 如果有商品，添加商品
-	if len(content.Products) > 0 {
-		if err := addProducts(page, content.Products); err != nil {
-			return errors.Wrap(err, "添加商品失败")
+	if queries := buildProductQueries(content.Products, content.ProductIDs); len(queries) > 0 {
+		selected, skipped, err := addProducts(page, queries, content.ProductMatch, content.ProductPolicy)
+		if err != nil {
+			return nil, errors.Wrap(err, "添加商品失败")
+		}
+		result.Products = selected
+		result.Skipped = skipped
+	}
+
+	// 填写标题与正文（支持纯文本和图文）
+	editor, err := inputTitleAndContent(page, content.Title, content.Content)
+	if err != nil {
+		return nil, errors.Wrap(err, "小红书填写内容失败")
+	}
+
+	if len(content.Mentions) > 0 {
+		if err := inputMentions(page, editor, content.Mentions); err != nil {
+			return nil, errors.Wrap(err, "@用户失败")
+		}
+	}
+
+	if content.Location != "" {
+		if err := selectLocation(page, content.Location); err != nil {
+			return nil, errors.Wrap(err, "添加地点失败")
+		}
+	}
+
+	if content.IsOriginal {
+		if err := setOriginalDeclaration(page); err != nil {
+			return nil, errors.Wrap(err, "声明原创失败")
+		}
+	}
+
+	if err := setVisibility(page, content.Visibility); err != nil {
+		return nil, errors.Wrap(err, "设置可见范围失败")
+	}
+
+	if content.DryRun {
+		if err := fillDryRunResult(page, content, result); err != nil {
+			return nil, err
 		}
+		return result, nil
+	}
+
+	if content.BeforeSubmit != nil {
+		if err := content.BeforeSubmit(); err != nil {
+			return nil, err
+		}
+	}
+
+	confirmed, err := submitPublish(page)
+	if err != nil {
+		return nil, errors.Wrap(err, "小红书发布失败")
+	}
+	result.Unconfirmed = !confirmed
+
+	return result, nil
+}
+
+// fillDryRunResult 读回已填写的表单并与请求比对，截图，不点击发布按钮
+func fillDryRunResult(page *rod.Page, content PublishImageContent, result *PublishResult) error {
+	form, err := readFormSnapshot(page)
+	if err != nil {
+		return errors.Wrap(err, "读取表单内容失败")
+	}
+
+	form.Mismatches = compareForm(form, content, result.Products)
+	if len(form.Mismatches) > 0 {
+		slog.Warn("dry-run 表单内容与请求不一致", "mismatches", form.Mismatches)
 	}
 
-	// 提交发布（支持纯文本和图文）
-	if err := submitPublish(page, content.Title, content.Content); err != nil {
-		return errors.Wrap(err, "小红书发布失败")
+	screenshot, err := page.Screenshot(true, &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	})
+	if err != nil {
+		return errors.Wrap(err, "截图失败")
 	}
 
+	slog.Info("dry-run 完成，未提交发布", "title", form.Title, "images", form.ImageCount)
+
+	result.DryRun = true
+	result.Form = form
+	result.Screenshot = screenshot
 	return nil
 }
 
-func uploadImages(page *rod.Page, imagesPaths []string) error {
-	pp := page.Timeout(60 * time.Second) // 增加上传超时时间
+func readFormSnapshot(page *rod.Page) (*FormSnapshot, error) {
+	form := &FormSnapshot{}
 
-	// 等待上传输入框出现
-	uploadInput, err := pp.Element(".upload-input")
+	titleElem, err := page.Element("div.d-input input")
+	if err != nil {
+		return nil, errors.Wrap(err, "没有找到标题输入框")
+	}
+	title, err := titleElem.Property("value")
 	if err != nil {
-		return errors.Wrap(err, "找不到上传输入框")
+		return nil, errors.Wrap(err, "读取标题失败")
 	}
+	form.Title = title.String()
 
-	// 上传多个文件
-	uploadInput.MustSetFiles(imagesPaths...)
+	if contentElem, ok := getContentElement(page); ok {
+		text, err := contentElem.Text()
+		if err != nil {
+			return nil, errors.Wrap(err, "读取正文失败")
+		}
+		form.Content = strings.TrimSpace(text)
+	}
 
-	// 等待上传完成
-	time.Sleep(5 * time.Second)
+	if images, err := page.Elements(selectorUploadedImage); err == nil {
+		form.ImageCount = len(images)
+	}
+
+	if err := readFormOptions(page, form); err != nil {
+		return nil, err
+	}
+
+	return form, nil
+}
+
+// formOptions 表单中商品、可见范围、地点、@用户和原创声明的填写状态
+type formOptions struct {
+	Products   []string `json:"products"`
+	Visibility string   `json:"visibility"`
+	Location   string   `json:"location"`
+	Mentions   []string `json:"mentions"`
+	IsOriginal bool     `json:"isOriginal"`
+}
+
+// readFormOptions 读取表单中除标题、正文、图片外的填写状态
+func readFormOptions(page *rod.Page, form *FormSnapshot) error {
+	res, err := page.Eval(`() => {
+		const text = el => (el ? (el.innerText || el.textContent || '').trim() : '');
+		const withText = (selector, keyword) =>
+			Array.from(document.querySelectorAll(selector)).find(el => text(el).includes(keyword));
+
+		const products = Array.from(document.querySelectorAll(
+			'[class*="multi-good-select"] .sku-name, [class*="multi-good-select"] .good-name'
+		)).map(text).filter(Boolean);
+
+		let location = text(document.querySelector('div.address-input, div.location-select'));
+		if (location.includes('添加地点')) location = '';
+
+		const visibility = text(document.querySelector('div.permission-card-wrapper div.d-select'));
+
+		const editor = document.querySelector('div.ql-editor, [role="textbox"]');
+		const mentions = editor
+			? Array.from(editor.querySelectorAll('.mention, [data-type="mention"]')).map(text).filter(Boolean)
+			: [];
+
+		let isOriginal = false;
+		const original = withText('div.custom-switch-card, div.original-wrapper', '原创声明');
+		if (original) {
+			const input = original.querySelector('input[type="checkbox"]');
+			const sw = original.querySelector('.d-switch');
+			isOriginal = input ? input.checked : !!(sw && sw.classList.contains('checked'));
+		}
+
+		return { products, visibility, location, mentions, isOriginal };
+	}`)
+	if err != nil {
+		return errors.Wrap(err, "读取表单选项失败")
+	}
+
+	var options formOptions
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &options); err != nil {
+		return errors.Wrap(err, "解析表单选项失败")
+	}
+
+	form.Products = options.Products
+	form.Location = options.Location
+	form.IsOriginal = options.IsOriginal
+	form.Visibility = parseVisibilityLabel(options.Visibility)
+	for _, mention := range options.Mentions {
+		form.Mentions = append(form.Mentions, strings.TrimPrefix(mention, "@"))
+	}
 
 	return nil
 }
 
-func submitPublish(page *rod.Page, title, content string) error {
+// parseVisibilityLabel 将可见范围下拉框的文案转换为 Visibility，无法识别时原样返回
+func parseVisibilityLabel(label string) string {
+	label = strings.TrimSpace(label)
+	for visibility, text := range visibilityLabels {
+		if strings.Contains(label, text) {
+			return string(visibility)
+		}
+	}
+	return label
+}
+
+// compareForm 比对读回的表单与请求内容，返回不一致的字段说明。
+// products 为实际选中的商品，按失败处理策略跳过的商品不参与比对。
+func compareForm(form *FormSnapshot, content PublishImageContent, products []SelectedProduct) []string {
+	var mismatches []string
+	mismatch := func(format string, args ...any) {
+		mismatches = append(mismatches, fmt.Sprintf(format, args...))
+	}
 
-	titleElem := page.MustElement("div.d-input input")
-	titleElem.MustInput(title)
+	if form.Title != strings.TrimSpace(content.Title) {
+		mismatch("标题为 %q，请求为 %q", form.Title, content.Title)
+	}
 
-	time.Sleep(1 * time.Second)
+	// 正文末尾会追加@用户和话题，且编辑器会调整空白字符，只检查请求正文是否完整出现
+	if want := strings.Join(strings.Fields(content.Content), " "); !strings.Contains(strings.Join(strings.Fields(form.Content), " "), want) {
+		mismatch("正文与请求不一致")
+	}
 
-	if contentElem, ok := getContentElement(page); ok {
-		contentElem.MustInput(content)
-	} else {
-		return errors.New("没有找到内容输入框")
+	if form.ImageCount != len(content.ImagePaths) {
+		mismatch("图片数量为 %d，请求为 %d", form.ImageCount, len(content.ImagePaths))
 	}
 
-	time.Sleep(1 * time.Second)
+	for _, product := range products {
+		if !slices.Contains(form.Products, product.Name) {
+			mismatch("商品 %q 未出现在表单中", product.Name)
+		}
+	}
 
-	submitButton := page.MustElement("div.submit div.d-button-content")
-	submitButton.MustClick()
+	wantVisibility := content.Visibility
+	if wantVisibility == "" {
+		wantVisibility = VisibilityPublic
+	}
+	if form.Visibility != string(wantVisibility) {
+		mismatch("可见范围为 %q，请求为 %q", form.Visibility, wantVisibility)
+	}
 
-	time.Sleep(3 * time.Second)
+	if location := strings.TrimSpace(content.Location); location != "" && !strings.Contains(form.Location, location) {
+		mismatch("地点为 %q，请求为 %q", form.Location, location)
+	}
 
-	return nil
+	for _, nickname := range content.Mentions {
+		nickname = strings.TrimSpace(strings.TrimPrefix(nickname, "@"))
+		if nickname != "" && !slices.Contains(form.Mentions, nickname) {
+			mismatch("未@用户 %q", nickname)
+		}
+	}
+
+	if form.IsOriginal != content.IsOriginal {
+		mismatch("原创声明为 %t，请求为 %t", form.IsOriginal, content.IsOriginal)
+	}
+
+	return mismatches
+}
+
+// inputTitleAndContent 填写标题与正文，返回正文输入框供后续追加内容
+func inputTitleAndContent(page *rod.Page, title, content string) (*rod.Element, error) {
+
+	titleElem, err := page.Element("div.d-input input")
+	if err != nil {
+		return nil, errors.Wrap(err, "没有找到标题输入框")
+	}
+	if err := titleElem.Input(title); err != nil {
+		return nil, errors.Wrap(err, "输入标题失败")
+	}
+
+	if err := waitUntil(page.GetContext(), inputSettleTimeout, "标题输入完成", func() bool {
+		value, err := titleElem.Property("value")
+		return err == nil && value.String() != ""
+	}); err != nil {
+		return nil, err
+	}
+
+	contentElem, ok := getContentElement(page)
+	if !ok {
+		return nil, errors.New("没有找到内容输入框")
+	}
+
+	if err := contentElem.WaitVisible(); err != nil {
+		return nil, errors.Wrap(err, "正文输入框未就绪")
+	}
+	if err := contentElem.Input(content); err != nil {
+		return nil, errors.Wrap(err, "输入正文失败")
+	}
+
+	if err := waitUntil(page.GetContext(), inputSettleTimeout, "正文输入完成", func() bool {
+		text, err := contentElem.Text()
+		return err == nil && strings.TrimSpace(text) != ""
+	}); err != nil {
+		return nil, err
+	}
+
+	return contentElem, nil
+}
+
+// submitPublish 点击发布按钮并等待发布结果，只有点击前后的操作失败才返回错误。
+// 点击后在 submitTimeout 内没有看到成功页或成功提示时返回 confirmed=false，
+// 此时笔记可能已经发布，调用方不应按失败处理或重试
+func submitPublish(page *rod.Page) (confirmed bool, err error) {
+	submitButton, err := page.Element("div.submit div.d-button-content")
+	if err != nil {
+		return false, errors.Wrap(err, "没有找到发布按钮")
+	}
+
+	if err := submitButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return false, errors.Wrap(err, "点击发布按钮失败")
+	}
+
+	// 发布成功后页面会跳转到成功页或弹出成功提示
+	if err := waitUntil(page.GetContext(), submitTimeout, "发布结果", func() bool {
+		if info, err := page.Info(); err == nil && strings.Contains(info.URL, "success") {
+			return true
+		}
+
+		has, _, err := page.HasR("div, span", "发布成功")
+		return err == nil && has
+	}); err != nil {
+		slog.Warn("已点击发布，但未确认发布结果", "error", err)
+		return false, nil
+	}
+
+	return true, nil
 }
 
 // 查找内容输入框 - 使用Race方法处理两种样式
@@ -0,0 +1,254 @@
+package xiaohongshu
+
+import (
+	"slices"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// imageUploadTimeout 单张图片从选择文件到缩略图上传完成的最长等待时间
+	imageUploadTimeout = 60 * time.Second
+
+	// 上传图片的文件输入框，首张图片与追加图片使用不同的输入框
+	selectorImageInput = `.upload-input, .img-upload-area input[type="file"], input[type="file"][accept*="image"]`
+
+	// attrUploadIndex 标记缩略图对应的上传序号，用于校验和调整图片顺序
+	attrUploadIndex = `data-mcp-upload-index`
+)
+
+// orderImagesForCover 将封面图片移动到首位，其余图片保持原有顺序。
+// 发布页以第一张图片作为封面，coverIndex 越界时保持原顺序。
+func orderImagesForCover(paths []string, coverIndex int) []string {
+	if coverIndex <= 0 || coverIndex >= len(paths) {
+		return paths
+	}
+
+	ordered := make([]string, 0, len(paths))
+	ordered = append(ordered, paths[coverIndex])
+	ordered = append(ordered, paths[:coverIndex]...)
+	ordered = append(ordered, paths[coverIndex+1:]...)
+	return ordered
+}
+
+// ImageUpload 单张图片的上传耗时
+type ImageUpload struct {
+	Path       string `json:"path"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// uploadImages 逐张上传图片，每张等待缩略图上传完成后再上传下一张，
+// 最后校验缩略图顺序，与期望顺序不一致时通过拖拽调整
+func uploadImages(page *rod.Page, imagesPaths []string) ([]ImageUpload, error) {
+	uploads := make([]ImageUpload, 0, len(imagesPaths))
+
+	for i, path := range imagesPaths {
+		start := time.Now()
+
+		before, err := thumbnailCount(page)
+		if err != nil {
+			return uploads, errors.Wrap(err, "读取已上传图片失败")
+		}
+
+		uploadInput, err := page.Timeout(10 * time.Second).Element(selectorImageInput)
+		if err != nil {
+			return uploads, errors.Wrap(err, "找不到上传输入框")
+		}
+
+		if err := uploadInput.SetFiles([]string{path}); err != nil {
+			return uploads, errors.Wrapf(err, "选择第%d张图片失败", i+1)
+		}
+
+		if err := waitForThumbnail(page, before, i, imageUploadTimeout); err != nil {
+			return uploads, errors.Wrapf(err, "第%d张图片上传失败: %s", i+1, path)
+		}
+
+		elapsed := time.Since(start)
+		uploads = append(uploads, ImageUpload{Path: path, DurationMs: elapsed.Milliseconds()})
+		logrus.Infof("第%d张图片上传完成，耗时%s: %s", i+1, elapsed.Round(time.Millisecond), path)
+	}
+
+	return uploads, ensureThumbnailOrder(page, len(imagesPaths))
+}
+
+// thumbnailCount 返回已上传缩略图的数量
+func thumbnailCount(page *rod.Page) (int, error) {
+	thumbs, err := page.Elements(selectorUploadedImage)
+	if err != nil {
+		return 0, err
+	}
+	return len(thumbs), nil
+}
+
+// thumbnailOrder 按页面顺序返回每张缩略图的上传序号，没有标记的缩略图为 -1
+func thumbnailOrder(page *rod.Page) ([]int, error) {
+	res, err := page.Eval(`(selector, attr) => Array.from(document.querySelectorAll(selector)).map(el =>
+		el.hasAttribute(attr) ? Number(el.getAttribute(attr)) : -1
+	)`, selectorUploadedImage, attrUploadIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []int
+	for _, v := range res.Value.Arr() {
+		order = append(order, v.Int())
+	}
+	return order, nil
+}
+
+// thumbnailsUploading 判断是否还有缩略图处于上传中
+func thumbnailsUploading(page *rod.Page) (bool, error) {
+	res, err := page.Eval(`(selector) => Array.from(document.querySelectorAll(selector)).some(el =>
+		!!el.querySelector('.loading, .uploading, .progress, .mask-loading') ||
+		!el.querySelector('img[src]')
+	)`, selectorUploadedImage)
+	if err != nil {
+		return false, err
+	}
+	return res.Value.Bool(), nil
+}
+
+// waitForThumbnail 等待缩略图数量超过 before 且全部上传完成，然后给新缩略图标记上传序号 index。
+// 上传完成后缩略图地址会从本地预览变为 CDN 地址，因此按数量和位置识别新缩略图，而不是按地址
+func waitForThumbnail(page *rod.Page, before, index int, timeout time.Duration) error {
+	err := waitUntil(page.GetContext(), timeout, "图片上传完成", func() bool {
+		count, err := thumbnailCount(page)
+		if err != nil || count <= before {
+			return false
+		}
+
+		uploading, err := thumbnailsUploading(page)
+		return err == nil && !uploading
+	})
+	if err != nil {
+		return err
+	}
+
+	return markThumbnail(page, before, index)
+}
+
+// markThumbnail 给新上传的缩略图设置上传序号。优先选择唯一没有标记的缩略图，
+// 否则使用追加位置 position 上的缩略图
+func markThumbnail(page *rod.Page, position, index int) error {
+	res, err := page.Eval(`(selector, attr, position, index) => {
+		const thumbs = Array.from(document.querySelectorAll(selector));
+		const unmarked = thumbs.filter(el => !el.hasAttribute(attr));
+		const target = unmarked.length === 1 ? unmarked[0] : thumbs[position];
+		if (!target) return false;
+		target.setAttribute(attr, String(index));
+		return true;
+	}`, selectorUploadedImage, attrUploadIndex, position, index)
+	if err != nil {
+		return errors.Wrap(err, "标记缩略图失败")
+	}
+	if !res.Value.Bool() {
+		return errors.Errorf("没有找到第%d张图片的缩略图", index+1)
+	}
+	return nil
+}
+
+// ensureThumbnailOrder 校验缩略图顺序与上传顺序一致，不一致时拖拽到正确位置
+func ensureThumbnailOrder(page *rod.Page, count int) error {
+	for attempt := 0; attempt < count*2; attempt++ {
+		current, err := thumbnailOrder(page)
+		if err != nil {
+			return errors.Wrap(err, "读取缩略图顺序失败")
+		}
+
+		from, to := firstMisplaced(current, count)
+		if to < 0 {
+			return nil
+		}
+		if from < 0 {
+			return errors.Errorf("第%d张图片的缩略图已丢失", to+1)
+		}
+
+		logrus.Infof("图片顺序与期望不一致，将第%d张拖拽到第%d位", from+1, to+1)
+		if err := dragThumbnail(page, from, to); err != nil {
+			return errors.Wrap(err, "调整图片顺序失败")
+		}
+	}
+
+	return errors.New("多次调整后图片顺序仍与期望不一致")
+}
+
+// firstMisplaced 找到第一个位置不正确的缩略图，返回其当前位置与目标位置。
+// current 为页面上各缩略图的上传序号，期望第 i 位为序号 i。
+// 顺序正确时 to 为 -1；期望的缩略图不在页面上时 from 为 -1。
+func firstMisplaced(current []int, count int) (from, to int) {
+	for i := 0; i < count; i++ {
+		if i < len(current) && current[i] == i {
+			continue
+		}
+		for j, index := range current {
+			if index == i {
+				return j, i
+			}
+		}
+		return -1, i
+	}
+	return -1, -1
+}
+
+// dragThumbnail 通过鼠标拖拽将第 from 张缩略图移动到第 to 张的位置
+func dragThumbnail(page *rod.Page, from, to int) error {
+	thumbs, err := page.Elements(selectorUploadedImage)
+	if err != nil {
+		return err
+	}
+	if from >= len(thumbs) || to >= len(thumbs) {
+		return errors.New("缩略图数量与期望不一致")
+	}
+
+	before, err := thumbnailOrder(page)
+	if err != nil {
+		return err
+	}
+
+	start, err := elementCenter(thumbs[from])
+	if err != nil {
+		return err
+	}
+	end, err := elementCenter(thumbs[to])
+	if err != nil {
+		return err
+	}
+
+	mouse := page.Mouse
+	if err := mouse.MoveTo(start); err != nil {
+		return err
+	}
+	if err := mouse.Down(proto.InputMouseButtonLeft, 1); err != nil {
+		return err
+	}
+	if err := mouse.MoveLinear(end, 10); err != nil {
+		return err
+	}
+	if err := mouse.Up(proto.InputMouseButtonLeft, 1); err != nil {
+		return err
+	}
+
+	// 拖拽生效后缩略图顺序会变化，由调用方重新校验是否到达目标位置
+	return waitUntil(page.GetContext(), 3*time.Second, "缩略图拖拽生效", func() bool {
+		after, err := thumbnailOrder(page)
+		return err == nil && !slices.Equal(after, before)
+	})
+}
+
+func elementCenter(elem *rod.Element) (proto.Point, error) {
+	shape, err := elem.Shape()
+	if err != nil {
+		return proto.Point{}, err
+	}
+
+	box := shape.Box()
+	if box == nil {
+		return proto.Point{}, errors.New("无法获取元素位置")
+	}
+
+	return proto.Point{X: box.X + box.Width/2, Y: box.Y + box.Height/2}, nil
+}
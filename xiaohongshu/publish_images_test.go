@@ -0,0 +1,38 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderImagesForCover(t *testing.T) {
+	paths := []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"}
+
+	assert.Equal(t, paths, orderImagesForCover(paths, 0))
+	assert.Equal(t, []string{"c.jpg", "a.jpg", "b.jpg", "d.jpg"}, orderImagesForCover(paths, 2))
+	assert.Equal(t, []string{"d.jpg", "a.jpg", "b.jpg", "c.jpg"}, orderImagesForCover(paths, 3))
+	assert.Equal(t, paths, orderImagesForCover(paths, 4), "越界时保持原顺序")
+	assert.Equal(t, paths, orderImagesForCover(paths, -1), "越界时保持原顺序")
+	assert.Equal(t, []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"}, paths, "不修改原切片")
+}
+
+func TestFirstMisplaced(t *testing.T) {
+	tests := []struct {
+		name             string
+		current          []int
+		wantFrom, wantTo int
+	}{
+		{"顺序正确", []int{0, 1, 2}, -1, -1},
+		{"末尾交换", []int{0, 2, 1}, 2, 1},
+		{"首张错位", []int{1, 2, 0}, 2, 0},
+		{"缩略图丢失", []int{0, 2}, -1, 1},
+		{"未标记的缩略图", []int{0, -1, 1, 2}, 2, 1},
+	}
+
+	for _, test := range tests {
+		from, to := firstMisplaced(test.current, 3)
+		assert.Equal(t, test.wantFrom, from, test.name)
+		assert.Equal(t, test.wantTo, to, test.name)
+	}
+}
@@ -0,0 +1,414 @@
+package xiaohongshu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Visibility 笔记可见范围
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"  // 公开可见
+	VisibilityPrivate Visibility = "private" // 仅自己可见
+	VisibilityFriends Visibility = "friends" // 仅互关好友可见
+)
+
+// visibilityLabels 可见范围在发布页下拉框中对应的文案
+var visibilityLabels = map[Visibility]string{
+	VisibilityPublic:  "公开可见",
+	VisibilityPrivate: "仅自己可见",
+	VisibilityFriends: "仅互关好友可见",
+}
+
+// ParseVisibility 解析可见范围，空字符串视为公开
+func ParseVisibility(s string) (Visibility, error) {
+	v := Visibility(strings.ToLower(strings.TrimSpace(s)))
+	if v == "" {
+		return VisibilityPublic, nil
+	}
+
+	if _, ok := visibilityLabels[v]; !ok {
+		return "", errors.Errorf("不支持的可见范围: %s（可选 public、private、friends）", s)
+	}
+
+	return v, nil
+}
+
+const (
+	// @用户联想列表项
+	selectorMentionItem = "#mention-popover .mention-item, .mention-container .mention-item"
+	// #话题联想列表项
+	selectorTopicItem = "#creator-editor-topic-container .item"
+	// 地点搜索结果
+	selectorLocationOption = "div.d-select-dropdown .d-grid-item, div.d-dropdown-content .d-option"
+
+	// optionSettleTimeout 点击选项后等待页面状态更新的最长时间
+	optionSettleTimeout = 3 * time.Second
+)
+
+// errOptionNotMatched 联想列表或搜索结果中没有匹配的选项
+var errOptionNotMatched = errors.New("没有匹配的选项")
+
+// AmbiguousOptionError 输入匹配到多个联想或搜索选项，为避免选错不自动选择
+type AmbiguousOptionError struct {
+	Query      string
+	Candidates []string
+}
+
+func (e *AmbiguousOptionError) Error() string {
+	return fmt.Sprintf("%q 匹配到多个选项: %s，请使用完整名称", e.Query, strings.Join(e.Candidates, "、"))
+}
+
+// matchOption 在选项名称中查找与 query 匹配的唯一选项，返回其下标。
+// 名称完全相同（话题可带 # 前缀）的选项优先；allowPartial 为 true 时才接受名称包含 query 的选项。
+// 多个选项同等匹配时返回 *AmbiguousOptionError，只有部分匹配但不允许时返回列出候选的错误。
+func matchOption(names []string, query string, allowPartial bool) (int, error) {
+	var exact, partial []int
+	for i, name := range names {
+		switch {
+		case name == query || name == "#"+query:
+			exact = append(exact, i)
+		case strings.Contains(name, query):
+			partial = append(partial, i)
+		}
+	}
+
+	candidates := exact
+	if len(candidates) == 0 {
+		if !allowPartial && len(partial) > 0 {
+			return -1, errors.Wrapf(errOptionNotMatched, "没有名称为 %q 的选项，候选: %s", query, strings.Join(pick(names, partial), "、"))
+		}
+		candidates = partial
+	}
+
+	switch len(candidates) {
+	case 0:
+		return -1, errOptionNotMatched
+	case 1:
+		return candidates[0], nil
+	default:
+		return -1, &AmbiguousOptionError{Query: query, Candidates: pick(names, candidates)}
+	}
+}
+
+func pick(names []string, indexes []int) []string {
+	picked := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		picked = append(picked, names[i])
+	}
+	return picked
+}
+
+// hasVisible 判断页面上是否有可见的 selector 元素
+func hasVisible(page *rod.Page, selector string) bool {
+	res, err := page.Eval(`(selector) => Array.from(document.querySelectorAll(selector)).some(el => el.offsetParent !== null)`, selector)
+	return err == nil && res.Value.Bool()
+}
+
+// inputMentions 在正文末尾依次输入 @昵称，并从联想列表中选择对应用户
+func inputMentions(page *rod.Page, editor *rod.Element, nicknames []string) error {
+	for _, nickname := range nicknames {
+		nickname = strings.TrimSpace(strings.TrimPrefix(nickname, "@"))
+		if nickname == "" {
+			continue
+		}
+
+		if err := inputWithSuggestion(page, editor, " @"+nickname, selectorMentionItem, nickname); err != nil {
+			return errors.Wrapf(err, "@用户失败: %s", nickname)
+		}
+
+		logrus.Infof("已@用户: %s", nickname)
+	}
+
+	return nil
+}
+
+// inputTags 在正文末尾依次输入 #话题，并从联想列表中选择对应话题
+func inputTags(page *rod.Page, editor *rod.Element, tags []string) error {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.TrimPrefix(tag, "#"))
+		if tag == "" {
+			continue
+		}
+
+		if err := inputWithSuggestion(page, editor, " #"+tag, selectorTopicItem, tag); err != nil {
+			return errors.Wrapf(err, "添加话题失败: %s", tag)
+		}
+
+		logrus.Infof("已添加话题: %s", tag)
+	}
+
+	return nil
+}
+
+// inputWithSuggestion 在正文末尾输入文本，并点击联想列表中名称匹配的选项
+func inputWithSuggestion(page *rod.Page, editor *rod.Element, text, itemSelector, name string) error {
+	if err := editor.Focus(); err != nil {
+		return errors.Wrap(err, "聚焦正文输入框失败")
+	}
+
+	if err := page.Keyboard.Type(input.End); err != nil {
+		logrus.Debugf("移动光标到正文末尾失败: %v", err)
+	}
+
+	if err := page.InsertText(text); err != nil {
+		return errors.Wrap(err, "输入文本失败")
+	}
+
+	item, err := findSuggestion(page, itemSelector, name)
+	if err != nil {
+		return err
+	}
+
+	if err := item.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "点击联想选项失败")
+	}
+
+	// 选中后联想列表关闭，再继续输入下一项
+	return waitUntil(page.GetContext(), optionSettleTimeout, "联想列表关闭", func() bool {
+		return !hasVisible(page, itemSelector)
+	})
+}
+
+// findSuggestion 在联想列表中查找名称与 name 完全相同的选项，
+// 只有部分匹配或匹配到多个时返回列出候选的错误，避免 @小王 选中「小王子」
+func findSuggestion(page *rod.Page, itemSelector, name string) (*rod.Element, error) {
+	var found *rod.Element
+	matchErr := errOptionNotMatched
+
+	err := waitUntil(page.GetContext(), 8*time.Second, "联想列表", func() bool {
+		items, err := page.Elements(itemSelector)
+		if err != nil || len(items) == 0 {
+			return false
+		}
+
+		names := make([]string, len(items))
+		for i, item := range items {
+			names[i], _ = suggestionItemName(item)
+		}
+
+		// 联想列表随输入刷新，没有完全匹配时继续等待，超时后返回最后一次的结果
+		index, err := matchOption(names, name, false)
+		if err != nil {
+			matchErr = err
+			return false
+		}
+		found = items[index]
+		return true
+	})
+	if found != nil {
+		return found, nil
+	}
+	if ctxErr := page.GetContext().Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if errors.Is(matchErr, errOptionNotMatched) {
+		return nil, errors.Wrapf(matchErr, "未找到联想选项: %s（%v）", name, err)
+	}
+
+	return nil, matchErr
+}
+
+func suggestionItemName(item *rod.Element) (string, error) {
+	if has, nameElem, err := item.Has(".name, .nickname"); err == nil && has {
+		text, err := nameElem.Text()
+		return strings.TrimSpace(text), err
+	}
+
+	text, err := item.Text()
+	return strings.TrimSpace(text), err
+}
+
+// selectLocation 打开地点选择器，搜索并选择地点
+func selectLocation(page *rod.Page, keyword string) error {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil
+	}
+
+	trigger, err := page.Timeout(5*time.Second).ElementR("div.address-input, div.location-select, span", "添加地点")
+	if err != nil {
+		return errors.Wrap(err, "未找到添加地点入口")
+	}
+
+	if err := trigger.ScrollIntoView(); err != nil {
+		logrus.Debugf("滚动到添加地点入口失败: %v", err)
+	}
+
+	if err := trigger.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "点击添加地点失败")
+	}
+
+	// 点击后地点搜索框获得焦点
+	if err := waitUntil(page.GetContext(), optionSettleTimeout, "地点搜索框", func() bool {
+		res, err := page.Eval(`() => document.activeElement && document.activeElement.tagName === 'INPUT'`)
+		return err == nil && res.Value.Bool()
+	}); err != nil {
+		return err
+	}
+
+	if err := page.InsertText(keyword); err != nil {
+		return errors.Wrap(err, "输入地点关键词失败")
+	}
+
+	var target *rod.Element
+	matchErr := errOptionNotMatched
+	err = waitUntil(page.GetContext(), 10*time.Second, "地点搜索结果", func() bool {
+		options, err := page.Elements(selectorLocationOption)
+		if err != nil || len(options) == 0 {
+			return false
+		}
+
+		names := make([]string, len(options))
+		for i, option := range options {
+			names[i] = locationOptionName(option)
+		}
+
+		index, err := matchOption(names, keyword, true)
+		if err != nil {
+			matchErr = err
+			return false
+		}
+		target = options[index]
+		return true
+	})
+	if target == nil {
+		if ctxErr := page.GetContext().Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if errors.Is(matchErr, errOptionNotMatched) {
+			return errors.Wrapf(matchErr, "未搜索到地点: %s（%v）", keyword, err)
+		}
+		return matchErr
+	}
+
+	if err := target.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "选择地点失败")
+	}
+
+	if err := waitUntil(page.GetContext(), optionSettleTimeout, "地点下拉框关闭", func() bool {
+		return !hasVisible(page, selectorLocationOption)
+	}); err != nil {
+		return err
+	}
+
+	logrus.Infof("已选择地点: %s", keyword)
+	return nil
+}
+
+// locationOptionName 返回地点选项的名称，选项第一行为名称，其余为地址
+func locationOptionName(option *rod.Element) string {
+	text, err := option.Text()
+	if err != nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(name)
+}
+
+// setVisibility 设置笔记可见范围，公开为页面默认值无需操作
+func setVisibility(page *rod.Page, visibility Visibility) error {
+	if visibility == "" || visibility == VisibilityPublic {
+		return nil
+	}
+
+	label, ok := visibilityLabels[visibility]
+	if !ok {
+		return errors.Errorf("不支持的可见范围: %s", visibility)
+	}
+
+	selector, err := page.Timeout(5*time.Second).ElementR("div.permission-card-wrapper div.d-select, div.d-select", "公开可见")
+	if err != nil {
+		return errors.Wrap(err, "未找到可见范围设置")
+	}
+
+	if err := selector.ScrollIntoView(); err != nil {
+		logrus.Debugf("滚动到可见范围设置失败: %v", err)
+	}
+
+	if err := selector.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "打开可见范围下拉框失败")
+	}
+
+	option, err := page.Timeout(5*time.Second).ElementR("div.d-options-wrapper div.d-option, div.d-dropdown-content div.d-option", label)
+	if err != nil {
+		return errors.Wrapf(err, "未找到可见范围选项: %s", label)
+	}
+
+	if err := option.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrapf(err, "选择可见范围失败: %s", label)
+	}
+
+	if err := waitUntil(page.GetContext(), optionSettleTimeout, "可见范围更新", func() bool {
+		text, err := selector.Text()
+		return err == nil && strings.Contains(text, label)
+	}); err != nil {
+		return err
+	}
+
+	logrus.Infof("已设置可见范围: %s", label)
+	return nil
+}
+
+// setOriginalDeclaration 勾选原创声明，并确认弹出的声明协议
+func setOriginalDeclaration(page *rod.Page) error {
+	wrapper, err := page.Timeout(5*time.Second).ElementR("div.custom-switch-card, div.original-wrapper", "原创声明")
+	if err != nil {
+		return errors.Wrap(err, "未找到原创声明选项")
+	}
+
+	if err := wrapper.ScrollIntoView(); err != nil {
+		logrus.Debugf("滚动到原创声明失败: %v", err)
+	}
+
+	if originalChecked(wrapper) {
+		return nil
+	}
+
+	toggle, err := wrapper.Element(".d-switch, .d-checkbox, input[type='checkbox']")
+	if err != nil {
+		return errors.Wrap(err, "未找到原创声明开关")
+	}
+
+	if err := toggle.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return errors.Wrap(err, "点击原创声明开关失败")
+	}
+
+	// 首次声明原创时会弹出协议确认框
+	if agree, err := page.Timeout(3*time.Second).ElementR("div.d-modal button", "声明原创|确认|同意"); err == nil {
+		if checkbox, err := page.Element("div.d-modal .d-checkbox"); err == nil {
+			_ = checkbox.Click(proto.InputMouseButtonLeft, 1)
+		}
+		if err := agree.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return errors.Wrap(err, "确认原创声明失败")
+		}
+	}
+
+	if err := waitUntil(page.GetContext(), optionSettleTimeout, "原创声明生效", func() bool {
+		return originalChecked(wrapper)
+	}); err != nil {
+		return err
+	}
+
+	logrus.Info("已声明原创")
+	return nil
+}
+
+// originalChecked 判断原创声明开关是否已打开
+func originalChecked(wrapper *rod.Element) bool {
+	res, err := wrapper.Eval(`() => {
+		const input = this.querySelector('input[type="checkbox"]');
+		if (input) return input.checked;
+		const sw = this.querySelector('.d-switch');
+		return !!(sw && sw.classList.contains('checked'));
+	}`)
+	return err == nil && res.Value.Bool()
+}
@@ -0,0 +1,55 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchOption(t *testing.T) {
+	t.Run("exact match wins over partial", func(t *testing.T) {
+		index, err := matchOption([]string{"小王子", "小王", "小王同学"}, "小王", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, index)
+	})
+
+	t.Run("topic with hash prefix", func(t *testing.T) {
+		index, err := matchOption([]string{"#旅行日记", "#旅行"}, "旅行", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, index)
+	})
+
+	t.Run("partial only is rejected with candidates", func(t *testing.T) {
+		_, err := matchOption([]string{"小王子", "小王同学"}, "小王", false)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, errOptionNotMatched))
+		assert.Contains(t, err.Error(), "小王子、小王同学")
+	})
+
+	t.Run("duplicate exact names are ambiguous", func(t *testing.T) {
+		_, err := matchOption([]string{"小王", "小王"}, "小王", false)
+		var ambiguous *AmbiguousOptionError
+		require.True(t, errors.As(err, &ambiguous))
+		assert.Equal(t, []string{"小王", "小王"}, ambiguous.Candidates)
+	})
+
+	t.Run("single partial match allowed", func(t *testing.T) {
+		index, err := matchOption([]string{"故宫博物院", "北京南站"}, "故宫", true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, index)
+	})
+
+	t.Run("several partial matches are ambiguous", func(t *testing.T) {
+		_, err := matchOption([]string{"星巴克(国贸店)", "星巴克(三里屯店)"}, "星巴克", true)
+		var ambiguous *AmbiguousOptionError
+		require.True(t, errors.As(err, &ambiguous))
+		assert.Equal(t, []string{"星巴克(国贸店)", "星巴克(三里屯店)"}, ambiguous.Candidates)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := matchOption([]string{"北京南站"}, "故宫", true)
+		assert.True(t, errors.Is(err, errOptionNotMatched))
+	})
+}
@@ -1,7 +1,9 @@
 package xiaohongshu
 
 import (
+	"fmt"
 	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
@@ -9,39 +11,158 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/xpzouying/xiaohongshu-mcp/pkg/validator"
 )
 
-func addProducts(page *rod.Page, productKeywords []string) error {
-	keywords := make([]string, 0, len(productKeywords))
-	for _, keyword := range productKeywords {
-		trimmed := strings.TrimSpace(keyword)
-		if trimmed != "" {
-			keywords = append(keywords, trimmed)
+// ProductMatchMode 商品名称匹配方式
+type ProductMatchMode string
+
+const (
+	ProductMatchContains ProductMatchMode = "contains" // 名称包含关键词，不区分大小写（默认），有完全相同的名称时优先
+	ProductMatchExact    ProductMatchMode = "exact"    // 名称与关键词逐字节相同，区分大小写
+)
+
+// ParseProductMatchMode 解析商品匹配方式，空字符串视为 contains
+func ParseProductMatchMode(s string) (ProductMatchMode, error) {
+	switch m := ProductMatchMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return ProductMatchContains, nil
+	case ProductMatchContains, ProductMatchExact:
+		return m, nil
+	default:
+		return "", errors.Errorf("不支持的商品匹配方式: %s（可选 contains、exact）", s)
+	}
+}
+
+// ProductFailurePolicy 商品未能添加时的处理策略
+type ProductFailurePolicy string
+
+const (
+	ProductPolicyFail        ProductFailurePolicy = "fail"          // 任一商品添加失败即终止发布（默认）
+	ProductPolicySkipMissing ProductFailurePolicy = "skip-missing"  // 静默跳过未搜索到的商品，匹配到多个等其他错误仍终止
+	ProductPolicySkipAndWarn ProductFailurePolicy = "skip-and-warn" // 跳过所有添加失败的商品并记录警告
+)
+
+// ParseProductFailurePolicy 解析商品失败处理策略，空字符串视为 fail
+func ParseProductFailurePolicy(s string) (ProductFailurePolicy, error) {
+	switch p := ProductFailurePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return ProductPolicyFail, nil
+	case ProductPolicyFail, ProductPolicySkipMissing, ProductPolicySkipAndWarn:
+		return p, nil
+	default:
+		return "", errors.Errorf("不支持的商品失败处理策略: %s（可选 fail、skip-missing、skip-and-warn）", s)
+	}
+}
+
+// ProductQuery 待添加的商品，按商品ID或名称关键词查找
+type ProductQuery struct {
+	Keyword string
+	ID      string
+}
+
+func (q ProductQuery) String() string {
+	if q.ID != "" {
+		return "ID:" + q.ID
+	}
+	return q.Keyword
+}
+
+// SelectedProduct 实际选中的商品
+type SelectedProduct struct {
+	Query string `json:"query"`
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name"`
+}
+
+// AmbiguousProductError 关键词匹配到多个商品
+type AmbiguousProductError struct {
+	Query      string
+	Candidates []string
+}
+
+func (e *AmbiguousProductError) Error() string {
+	return fmt.Sprintf("商品关键词 %q 匹配到多个商品: %s，请使用更精确的名称、exact 模式或商品ID", e.Query, strings.Join(e.Candidates, "、"))
+}
+
+// SkippedProduct 按失败处理策略跳过的商品
+type SkippedProduct struct {
+	Query  string `json:"query"`
+	Reason string `json:"reason"`
+}
+
+var errProductNotMatched = errors.New("没有匹配的商品")
+
+// shouldSkipProduct 根据策略判断添加失败的商品能否跳过
+func shouldSkipProduct(policy ProductFailurePolicy, err error) bool {
+	switch policy {
+	case ProductPolicySkipAndWarn:
+		return true
+	case ProductPolicySkipMissing:
+		return errors.Is(err, errProductNotMatched)
+	default:
+		return false
+	}
+}
+
+// productCardInfo 商品卡片上展示的商品信息
+type productCardInfo struct {
+	ID   string
+	Name string
+}
+
+var productIDPattern = regexp.MustCompile(`ID[:：]\s*([0-9a-zA-Z]+)`)
+
+// productSearchTimeout 输入搜索词后等待商品列表刷新的最长时间
+const productSearchTimeout = 5 * time.Second
+
+// buildProductQueries 合并商品ID与名称关键词，忽略空值
+func buildProductQueries(keywords, ids []string) []ProductQuery {
+	queries := make([]ProductQuery, 0, len(keywords)+len(ids))
+	for _, id := range ids {
+		if trimmed := strings.TrimSpace(id); trimmed != "" {
+			queries = append(queries, ProductQuery{ID: trimmed})
+		}
+	}
+	for _, keyword := range keywords {
+		if trimmed := strings.TrimSpace(keyword); trimmed != "" {
+			queries = append(queries, ProductQuery{Keyword: trimmed})
 		}
 	}
+	return queries
+}
 
-	if len(keywords) == 0 {
-		return nil
+// addProducts 在商品选择弹窗中依次搜索并勾选商品，按 policy 决定添加失败的商品是否跳过
+func addProducts(page *rod.Page, queries []ProductQuery, mode ProductMatchMode, policy ProductFailurePolicy) ([]SelectedProduct, []SkippedProduct, error) {
+	if len(queries) == 0 {
+		return nil, nil, nil
+	}
+
+	if len(queries) > validator.MaxProducts {
+		return nil, nil, errors.Errorf("商品数量%d超过上限%d", len(queries), validator.MaxProducts)
 	}
 
 	addButton, err := findAddProductButton(page)
 	if err != nil {
-		return errors.Wrap(err, "未找到添加商品入口")
+		return nil, nil, errors.Wrap(err, "未找到添加商品入口")
 	}
 
 	if err := addButton.ScrollIntoView(); err != nil {
 		logrus.Debugf("滚动到添加商品按钮失败: %v", err)
 	}
-	time.Sleep(100 * time.Millisecond)
+	if err := waitUntil(page.GetContext(), 3*time.Second, "添加商品按钮可见", func() bool {
+		return isElementVisible(addButton)
+	}); err != nil {
+		return nil, nil, err
+	}
 
 	if err := addButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return errors.Wrap(err, "点击添加商品按钮失败")
+		return nil, nil, errors.Wrap(err, "点击添加商品按钮失败")
 	}
-	time.Sleep(500 * time.Millisecond)
 
 	modal, err := page.Timeout(15 * time.Second).Element("div.multi-goods-selector-modal")
 	if err != nil {
-		return errors.Wrap(err, "打开商品选择弹窗失败")
+		return nil, nil, errors.Wrap(err, "打开商品选择弹窗失败")
 	}
 
 	if err := waitForProductListLoad(modal); err != nil {
@@ -50,40 +171,67 @@ func addProducts(page *rod.Page, productKeywords []string) error {
 
 	searchInput, err := modal.Timeout(10 * time.Second).Element("input[placeholder='搜索商品ID 或 商品名称']")
 	if err != nil {
-		return errors.Wrap(err, "未找到商品搜索输入框")
+		return nil, nil, errors.Wrap(err, "未找到商品搜索输入框")
 	}
 
-	for _, keyword := range keywords {
-		if err := inputProductSearchKeyword(searchInput, keyword); err != nil {
-			return errors.Wrapf(err, "搜索商品失败: %s", keyword)
+	selected := make([]SelectedProduct, 0, len(queries))
+	var skipped []SkippedProduct
+	for _, query := range queries {
+		search := query.Keyword
+		if query.ID != "" {
+			search = query.ID
 		}
 
-		card, err := findProductCard(modal, keyword)
-		if err != nil {
-			return errors.Wrapf(err, "未找到匹配商品: %s", keyword)
+		before := productListSignature(modal)
+		if err := inputProductSearchKeyword(searchInput, search); err != nil {
+			return nil, nil, errors.Wrapf(err, "搜索商品失败: %s", query)
+		}
+		// 列表未刷新时读到的仍是上一次的结果，可能误选其他商品
+		if err := waitForProductSearch(modal, before); err != nil {
+			logrus.Debugf("%v，搜索结果可能与上一次相同: %s", err, query)
 		}
 
-		if err := ensureProductSelected(card); err != nil {
-			return errors.Wrapf(err, "选择商品失败: %s", keyword)
+		card, info, err := findProductCard(modal, query, mode)
+		if err == nil {
+			err = errors.Wrap(ensureProductSelected(card), "选择商品失败")
+		}
+		if err != nil {
+			if !shouldSkipProduct(policy, err) {
+				return nil, nil, errors.Wrapf(err, "添加商品失败: %s", query)
+			}
+			if policy == ProductPolicySkipAndWarn {
+				logrus.Warnf("跳过商品 %s: %v", query, err)
+			} else {
+				logrus.Debugf("跳过未搜索到的商品 %s: %v", query, err)
+			}
+			skipped = append(skipped, SkippedProduct{Query: query.String(), Reason: err.Error()})
+			continue
 		}
 
-		logrus.Infof("已选中商品: %s", keyword)
+		selected = append(selected, SelectedProduct{Query: query.String(), ID: info.ID, Name: info.Name})
+		logrus.Infof("已选中商品: %s -> %s (%s)", query, info.Name, info.ID)
+	}
+
+	// 全部商品都被跳过时直接关闭弹窗
+	if len(selected) == 0 {
+		closeProductModal(page, modal)
+		return selected, skipped, nil
 	}
 
 	saveButton, err := modal.ElementR("div.d-modal-footer button", "保存")
 	if err != nil {
-		return errors.Wrap(err, "未找到商品保存按钮")
+		return nil, nil, errors.Wrap(err, "未找到商品保存按钮")
 	}
 
 	if err := saveButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
-		return errors.Wrap(err, "点击保存商品按钮失败")
+		return nil, nil, errors.Wrap(err, "点击保存商品按钮失败")
 	}
 
 	if err := waitForModalClose(page); err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return nil
+	return selected, skipped, nil
 }
 
 func findAddProductButton(page *rod.Page) (*rod.Element, error) {
@@ -120,41 +268,141 @@ func inputProductSearchKeyword(input *rod.Element, keyword string) error {
 		return err
 	}
 
-	time.Sleep(500 * time.Millisecond)
 	return nil
 }
 
-func findProductCard(modal *rod.Element, keyword string) (*rod.Element, error) {
-	deadline := time.Now().Add(10 * time.Second)
-	lowerKeyword := strings.ToLower(keyword)
+// productListSignature 返回当前商品列表的内容摘要，用于判断搜索后列表是否已刷新
+func productListSignature(modal *rod.Element) string {
+	_, infos, err := readProductCards(modal)
+	if err != nil {
+		return ""
+	}
 
-	for time.Now().Before(deadline) {
-		cards, err := modal.Elements(".good-card-container")
+	parts := make([]string, 0, len(infos))
+	for _, info := range infos {
+		parts = append(parts, info.ID+"|"+info.Name)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// waitForProductSearch 等待商品列表内容与搜索前的 before 不同
+func waitForProductSearch(modal *rod.Element, before string) error {
+	return waitUntil(modal.GetContext(), productSearchTimeout, "商品搜索结果刷新", func() bool {
+		return productListSignature(modal) != before
+	})
+}
+
+func findProductCard(modal *rod.Element, query ProductQuery, mode ProductMatchMode) (*rod.Element, productCardInfo, error) {
+	var (
+		card    *rod.Element
+		info    productCardInfo
+		lastErr = errProductNotMatched
+	)
+
+	err := waitUntil(modal.GetContext(), 10*time.Second, "商品搜索结果", func() bool {
+		cards, infos, err := readProductCards(modal)
 		if err != nil || len(cards) == 0 {
-			time.Sleep(300 * time.Millisecond)
-			continue
+			return false
 		}
 
-		for _, card := range cards {
-			nameElem, err := card.Element(".sku-name")
-			if err != nil {
-				continue
+		index, err := matchProductCard(infos, query, mode)
+		if err == nil {
+			card, info = cards[index], infos[index]
+			return true
+		}
+		lastErr = err
+
+		// 匹配到多个商品时不再等待，直接返回候选
+		var ambiguous *AmbiguousProductError
+		return errors.As(err, &ambiguous)
+	})
+	if card != nil {
+		return card, info, nil
+	}
+	if ctxErr := modal.GetContext().Err(); ctxErr != nil {
+		return nil, productCardInfo{}, ctxErr
+	}
+
+	var ambiguous *AmbiguousProductError
+	if errors.As(lastErr, &ambiguous) {
+		return nil, productCardInfo{}, lastErr
+	}
+	return nil, productCardInfo{}, errors.Wrapf(lastErr, "未找到商品: %s（%v）", query, err)
+}
+
+// readProductCards 读取弹窗中当前展示的商品卡片及其ID、名称
+func readProductCards(modal *rod.Element) ([]*rod.Element, []productCardInfo, error) {
+	cards, err := modal.Elements(".good-card-container")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	infos := make([]productCardInfo, len(cards))
+	for i, card := range cards {
+		if has, nameElem, err := card.Has(".sku-name"); err == nil && has {
+			if name, err := nameElem.Text(); err == nil {
+				infos[i].Name = strings.TrimSpace(name)
 			}
+		}
 
-			name, err := nameElem.Text()
-			if err != nil {
-				continue
+		if text, err := card.Text(); err == nil {
+			if match := productIDPattern.FindStringSubmatch(text); match != nil {
+				infos[i].ID = match[1]
 			}
+		}
+	}
+
+	return cards, infos, nil
+}
 
-			if strings.Contains(strings.ToLower(name), lowerKeyword) {
-				return card, nil
+// matchProductCard 在商品卡片中查找与查询匹配的唯一商品，返回其下标。
+// 多个商品同等匹配时返回 *AmbiguousProductError。
+func matchProductCard(infos []productCardInfo, query ProductQuery, mode ProductMatchMode) (int, error) {
+	if query.ID != "" {
+		for i, info := range infos {
+			if info.ID == query.ID {
+				return i, nil
 			}
 		}
+		return -1, errProductNotMatched
+	}
+
+	var exact, partial []int
+	lowerKeyword := strings.ToLower(query.Keyword)
+	for i, info := range infos {
+		if mode == ProductMatchExact {
+			if info.Name == query.Keyword {
+				exact = append(exact, i)
+			}
+			continue
+		}
+
+		lowerName := strings.ToLower(info.Name)
+		switch {
+		case lowerName == lowerKeyword:
+			exact = append(exact, i)
+		case strings.Contains(lowerName, lowerKeyword):
+			partial = append(partial, i)
+		}
+	}
 
-		time.Sleep(300 * time.Millisecond)
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = partial
 	}
 
-	return nil, errors.Errorf("未找到商品: %s", keyword)
+	switch len(candidates) {
+	case 0:
+		return -1, errProductNotMatched
+	case 1:
+		return candidates[0], nil
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, i := range candidates {
+			names = append(names, infos[i].Name)
+		}
+		return -1, &AmbiguousProductError{Query: query.Keyword, Candidates: names}
+	}
 }
 
 func ensureProductSelected(card *rod.Element) error {
@@ -177,8 +425,8 @@ func ensureProductSelected(card *rod.Element) error {
 		return false;
 	}`); err == nil {
 		if !res.Value.Bool() {
-			logrus.Warn("检测到空的选择框元素，跳过此商品")
-			return nil
+			// 空的选择框无法勾选，交给 product_policy 决定跳过还是失败
+			return errors.New("商品选择框为空，无法勾选")
 		}
 	}
 
@@ -189,7 +437,6 @@ func ensureProductSelected(card *rod.Element) error {
 	if err := card.ScrollIntoView(); err != nil {
 		logrus.Debugf("滚动商品卡片失败: %v", err)
 	}
-	time.Sleep(100 * time.Millisecond)
 
 	checkboxArea, err := findCheckboxArea(card)
 	if err != nil {
@@ -229,9 +476,14 @@ func ensureProductSelected(card *rod.Element) error {
 		},
 	}
 
+	checked := func() bool {
+		res, err := checkboxInput.Eval("() => this.checked")
+		return err == nil && res.Value.Bool()
+	}
+
 	var lastErr error
 	for attempt := 0; attempt < 3; attempt++ {
-		if res, err := checkboxInput.Eval("() => this.checked"); err == nil && res.Value.Bool() {
+		if checked() {
 			return nil
 		}
 
@@ -242,14 +494,15 @@ func ensureProductSelected(card *rod.Element) error {
 				continue
 			}
 
-			time.Sleep(200 * time.Millisecond)
-
-			if res, err := checkboxInput.Eval("() => this.checked"); err == nil && res.Value.Bool() {
+			err := waitUntil(card.GetContext(), time.Second, "商品勾选生效", checked)
+			if err == nil {
 				return nil
 			}
+			if ctxErr := card.GetContext().Err(); ctxErr != nil {
+				return ctxErr
+			}
+			lastErr = err
 		}
-
-		time.Sleep(300 * time.Millisecond)
 	}
 
 	return errors.Wrapf(lastErr, "商品选择失败（已尝试3次）")
@@ -282,45 +535,30 @@ func findVisibleElement(elems []*rod.Element) (*rod.Element, error) {
 }
 
 func waitForProductListLoad(modal *rod.Element) error {
-	deadline := time.Now().Add(10 * time.Second)
-
-	for time.Now().Before(deadline) {
+	return waitUntil(modal.GetContext(), 10*time.Second, "商品列表加载", func() bool {
 		cards, err := modal.Elements(".good-card-container")
-		if err == nil && len(cards) > 0 {
-			for _, card := range cards {
-				if isElementVisible(card) {
-					return nil
-				}
+		if err == nil {
+			if _, err := findVisibleElement(cards); err == nil {
+				return true
 			}
 		}
 
 		emptyStates, err := modal.Elements(".goods-list-empty, .goods-list-search-empty")
 		if err == nil {
-			for _, empty := range emptyStates {
-				if isElementVisible(empty) {
-					return nil
-				}
+			if _, err := findVisibleElement(emptyStates); err == nil {
+				return true
 			}
 		}
 
-		time.Sleep(200 * time.Millisecond)
-	}
-
-	return errors.New("等待商品列表加载超时")
+		return false
+	})
 }
 
 func waitForModalClose(page *rod.Page) error {
-	deadline := time.Now().Add(5 * time.Second)
-
-	for time.Now().Before(deadline) {
+	return waitUntil(page.GetContext(), 5*time.Second, "商品选择弹窗关闭", func() bool {
 		has, _, err := page.Has("div.multi-goods-selector-modal")
-		if err == nil && !has {
-			return nil
-		}
-		time.Sleep(200 * time.Millisecond)
-	}
-
-	return errors.New("关闭商品选择弹窗超时")
+		return err == nil && !has
+	})
 }
 
 func isElementVisible(elem *rod.Element) bool {
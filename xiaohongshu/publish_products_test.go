@@ -0,0 +1,89 @@
+package xiaohongshu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchProductCard(t *testing.T) {
+	cards := []productCardInfo{
+		{ID: "1001", Name: "正宗特级湘西莓茶"},
+		{ID: "1002", Name: "蒲菊枸杞决明子茶"},
+		{ID: "1003", Name: "莓茶"},
+	}
+
+	index, err := matchProductCard(cards, ProductQuery{ID: "1002"}, ProductMatchContains)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	index, err = matchProductCard(cards, ProductQuery{Keyword: "决明子"}, ProductMatchContains)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	// 完全相同的名称优先于包含匹配
+	index, err = matchProductCard(cards, ProductQuery{Keyword: "莓茶"}, ProductMatchContains)
+	require.NoError(t, err)
+	assert.Equal(t, 2, index)
+
+	_, err = matchProductCard(cards, ProductQuery{Keyword: "茶"}, ProductMatchContains)
+	var ambiguous *AmbiguousProductError
+	require.True(t, errors.As(err, &ambiguous), "expected ambiguous error, got %v", err)
+	assert.Len(t, ambiguous.Candidates, 3)
+
+	_, err = matchProductCard(cards, ProductQuery{Keyword: "决明子"}, ProductMatchExact)
+	assert.ErrorIs(t, err, errProductNotMatched)
+
+	_, err = matchProductCard(cards, ProductQuery{ID: "9999"}, ProductMatchContains)
+	assert.ErrorIs(t, err, errProductNotMatched)
+
+	// 卡片未展示ID时无法确认是目标商品，不视为命中
+	_, err = matchProductCard([]productCardInfo{{Name: "儿时麦芽糖"}}, ProductQuery{ID: "2001"}, ProductMatchContains)
+	assert.ErrorIs(t, err, errProductNotMatched)
+
+	// exact 模式区分大小写，contains 模式不区分
+	latin := []productCardInfo{{ID: "3001", Name: "Green Tea"}}
+	_, err = matchProductCard(latin, ProductQuery{Keyword: "green tea"}, ProductMatchExact)
+	assert.ErrorIs(t, err, errProductNotMatched)
+
+	index, err = matchProductCard(latin, ProductQuery{Keyword: "Green Tea"}, ProductMatchExact)
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+
+	index, err = matchProductCard(latin, ProductQuery{Keyword: "green tea"}, ProductMatchContains)
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+}
+
+func TestParseProductMatchMode(t *testing.T) {
+	mode, err := ParseProductMatchMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ProductMatchContains, mode)
+
+	mode, err = ParseProductMatchMode("EXACT")
+	require.NoError(t, err)
+	assert.Equal(t, ProductMatchExact, mode)
+
+	_, err = ParseProductMatchMode("fuzzy")
+	assert.Error(t, err)
+}
+
+func TestShouldSkipProduct(t *testing.T) {
+	notMatched := fmt.Errorf("未找到商品: 莓茶: %w", errProductNotMatched)
+	ambiguous := &AmbiguousProductError{Query: "茶", Candidates: []string{"莓茶", "花茶"}}
+
+	assert.False(t, shouldSkipProduct(ProductPolicyFail, notMatched))
+	assert.True(t, shouldSkipProduct(ProductPolicySkipMissing, notMatched))
+	assert.False(t, shouldSkipProduct(ProductPolicySkipMissing, ambiguous))
+	assert.True(t, shouldSkipProduct(ProductPolicySkipAndWarn, ambiguous))
+
+	policy, err := ParseProductFailurePolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, ProductPolicyFail, policy)
+
+	_, err = ParseProductFailurePolicy("ignore")
+	assert.Error(t, err)
+}
@@ -23,10 +23,48 @@ func TestPublish(t *testing.T) {
 	action, err := NewPublishImageAction(page)
 	require.NoError(t, err)
 
-	err = action.Publish(context.Background(), PublishImageContent{
+	_, err = action.Publish(context.Background(), PublishImageContent{
 		Title:      "Hello World",
 		Content:    "Hello World",
 		ImagePaths: []string{"/tmp/1.jpg"},
 	})
 	assert.NoError(t, err)
 }
+
+func TestCompareForm(t *testing.T) {
+	content := PublishImageContent{
+		Title:      "标题",
+		Content:    "第一行\n第二行",
+		ImagePaths: []string{"a.jpg", "b.jpg"},
+		Visibility: VisibilityPrivate,
+		Location:   "上海",
+		Mentions:   []string{"@小红"},
+		IsOriginal: true,
+	}
+	products := []SelectedProduct{{Query: "莓茶", Name: "湘西莓茶"}}
+
+	form := &FormSnapshot{
+		Title:      "标题",
+		Content:    "第一行 第二行 @小红",
+		ImageCount: 2,
+		Products:   []string{"湘西莓茶"},
+		Visibility: "private",
+		Location:   "上海市静安区",
+		Mentions:   []string{"小红"},
+		IsOriginal: true,
+	}
+	assert.Empty(t, compareForm(form, content, products))
+
+	form.Visibility = "public"
+	form.Products = nil
+	form.Mentions = nil
+	form.IsOriginal = false
+	assert.Len(t, compareForm(form, content, products), 4)
+}
+
+func TestParseVisibilityLabel(t *testing.T) {
+	assert.Equal(t, "public", parseVisibilityLabel("公开可见"))
+	assert.Equal(t, "private", parseVisibilityLabel(" 仅自己可见 "))
+	assert.Equal(t, "friends", parseVisibilityLabel("仅互关好友可见"))
+	assert.Equal(t, "", parseVisibilityLabel(""))
+}
@@ -2,57 +2,404 @@ package xiaohongshu
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+const (
+	// DefaultSearchLimit 未指定数量时返回的结果数，约为首屏数量
+	DefaultSearchLimit = 20
+	// MaxSearchLimit 单次请求最多返回的结果数
+	MaxSearchLimit = 200
+
+	// defaultSearchMaxPages 未指定时最多滚动加载的页数
+	defaultSearchMaxPages = 20
+	// searchScrollTimeout 每次滚动后等待新结果加载的最长时间
+	searchScrollTimeout = 5 * time.Second
+	// searchScrollAttempts 滚动后没有新结果时的尝试次数，全部失败才视为没有更多结果
+	searchScrollAttempts = 3
+
+	// searchRequestTimeout 单次搜索请求中页面操作的最长时间
+	searchRequestTimeout = 60 * time.Second
+	// searchSessionTTL 搜索会话空闲多久后关闭页面，超过后游标回退为重新搜索
+	searchSessionTTL = 5 * time.Minute
+	// maxSearchSessions 同时保留的搜索会话数量
+	maxSearchSessions = 5
+)
+
+// ErrInvalidCursor 分页游标格式错误
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
 type SearchResult struct {
 	Search struct {
 		Feeds FeedsValue `json:"feeds"`
 	} `json:"search"`
 }
 
-type SearchAction struct {
-	page *rod.Page
+// SearchOptions 搜索分页与筛选参数
+type SearchOptions struct {
+	Limit    int    // 返回结果数量，<=0 时为 DefaultSearchLimit
+	Cursor   string // 上一页返回的游标，为空时从第一条开始
+	MaxPages int    // 最多滚动加载的页数，<=0 时使用默认值
+
+	Filters SearchFilters // 排序、笔记类型、发布时间筛选
+}
+
+// SearchPage 一页搜索结果
+type SearchPage struct {
+	Feeds      []Feed `json:"feeds"`
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，没有更多结果时为空
+	HasMore    bool   `json:"has_more"`
 }
 
-func NewSearchAction(page *rod.Page) *SearchAction {
-	pp := page.Timeout(60 * time.Second)
+// searchSession 一次搜索的翻页状态：保留打开的搜索页面和已加载的结果，
+// 翻页时在同一页面上继续滚动，不必重新搜索并从头滚动到游标位置
+type searchSession struct {
+	id        string
+	key       string    // 关键词与筛选条件，游标只能用于相同的搜索
+	page      *rod.Page // 不绑定请求 ctx，会话期间一直打开
+	collector *responseCollector
+	feeds     []Feed
+	seen      map[string]bool
+	exhausted bool
+	lastUsed  time.Time
+}
 
-	return &SearchAction{page: pp}
+func (ss *searchSession) close() {
+	ss.collector.Stop()
+	if err := ss.page.Close(); err != nil {
+		logrus.Debugf("关闭搜索页面失败: %v", err)
+	}
 }
 
-func (s *SearchAction) Search(ctx context.Context, keyword string) ([]Feed, error) {
-	page := s.page.Context(ctx)
+// SearchSessions 管理搜索会话，空闲超过 searchSessionTTL 的会话会关闭页面
+type SearchSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*searchSession
+	newPage  func() *rod.Page
+}
 
-	searchURL := makeSearchURL(keyword)
-	page.MustNavigate(searchURL)
-	page.MustWaitStable()
+// NewSearchSessions 创建搜索会话管理器，newPage 用于为新的搜索打开页面
+func NewSearchSessions(newPage func() *rod.Page) *SearchSessions {
+	return &SearchSessions{
+		sessions: make(map[string]*searchSession),
+		newPage:  newPage,
+	}
+}
 
-	page.MustWait(`() => window.__INITIAL_STATE__ !== undefined`)
+// Search 搜索笔记，结果不足时滚动页面加载更多，按 Feed.ID 去重。
+// 游标记录搜索会话和已返回的结果数量，会话仍在时在原页面上继续加载；
+// 会话已过期时重新搜索并跳过之前返回的结果。
+func (s *SearchSessions) Search(ctx context.Context, keyword string, opts SearchOptions) (*SearchPage, error) {
+	sessionID, offset, err := parseSearchCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultSearchMaxPages
+	}
+
+	key := fmt.Sprintf("%s|%+v", keyword, opts.Filters)
+	session := s.take(sessionID, key)
+	if session == nil {
+		if session, err = s.open(ctx, keyword, key, opts.Filters); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := session.load(ctx, offset+limit, maxPages); err != nil {
+		session.close()
+		return nil, err
+	}
+
+	result := paginateFeeds(session.feeds, offset, limit, session.exhausted, session.id)
+	if result.HasMore {
+		s.put(session)
+	} else {
+		session.close()
+	}
+
+	return result, nil
+}
 
-	// 获取 window.__INITIAL_STATE__ 并转换为 JSON 字符串
-	result := page.MustEval(`() => {
-			if (window.__INITIAL_STATE__) {
-				return JSON.stringify(window.__INITIAL_STATE__);
+// take 取出游标对应的会话，会话不存在、已失效或搜索条件不同时返回 nil。
+// 取出的会话在放回前不会被其他请求使用。
+func (s *SearchSessions) take(id, key string) *searchSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil
+	}
+	delete(s.sessions, id)
+
+	if session.key != key {
+		session.close()
+		return nil
+	}
+
+	// 浏览器重启后原页面不可用
+	if _, err := session.page.Info(); err != nil {
+		logrus.Debugf("搜索会话页面已失效: %v", err)
+		session.close()
+		return nil
+	}
+
+	return session
+}
+
+// put 放回会话供下一页使用，超过 maxSearchSessions 时关闭最久未使用的会话
+func (s *SearchSessions) put(session *searchSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session.lastUsed = time.Now()
+	s.sessions[session.id] = session
+
+	for len(s.sessions) > maxSearchSessions {
+		var oldest *searchSession
+		for _, ss := range s.sessions {
+			if oldest == nil || ss.lastUsed.Before(oldest.lastUsed) {
+				oldest = ss
 			}
-			return "";
-		}`).String()
+		}
+		delete(s.sessions, oldest.id)
+		oldest.close()
+	}
+}
+
+func (s *SearchSessions) pruneLocked() {
+	cutoff := time.Now().Add(-searchSessionTTL)
+	for id, session := range s.sessions {
+		if session.lastUsed.Before(cutoff) {
+			delete(s.sessions, id)
+			session.close()
+		}
+	}
+}
+
+// open 打开搜索页面、应用筛选条件并读取首屏结果
+func (s *SearchSessions) open(ctx context.Context, keyword, key string, filters SearchFilters) (*searchSession, error) {
+	id, err := newSearchSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := s.newPage()
+	page := raw.Context(ctx).Timeout(searchRequestTimeout)
+
+	fail := func(err error) (*searchSession, error) {
+		if err := raw.Close(); err != nil {
+			logrus.Debugf("关闭搜索页面失败: %v", err)
+		}
+		return nil, err
+	}
+
+	if err := page.Navigate(makeSearchURL(keyword)); err != nil {
+		return fail(errors.Wrap(err, "打开搜索页面失败"))
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return fail(errors.Wrap(err, "等待搜索页面加载失败"))
+	}
 
-	if result == "" {
+	if err := waitUntil(ctx, 15*time.Second, "搜索页数据加载", func() bool {
+		res, err := page.Eval(`() => window.__INITIAL_STATE__ !== undefined`)
+		return err == nil && res.Value.Bool()
+	}); err != nil {
+		return fail(err)
+	}
+
+	if err := applySearchFilters(page, filters); err != nil {
+		return fail(errors.Wrap(err, "应用搜索筛选失败"))
+	}
+
+	// 筛选后再开始监听，避免混入筛选前的接口响应；监听不绑定请求 ctx，翻页时继续使用
+	session := &searchSession{
+		id:        id,
+		key:       key,
+		page:      raw,
+		collector: startResponseCollector(raw),
+		seen:      make(map[string]bool),
+	}
+
+	initial, err := readSearchFeeds(page)
+	if err != nil {
+		session.close()
+		return nil, err
+	}
+	session.feeds = mergeFeeds(nil, session.seen, initial)
+
+	return session, nil
+}
+
+// load 滚动加载直到结果数量达到 want、滚动 maxPages 次或没有更多结果
+func (ss *searchSession) load(ctx context.Context, want, maxPages int) error {
+	page := ss.page.Context(ctx).Timeout(searchRequestTimeout)
+
+	for pageNum := 1; len(ss.feeds) < want && !ss.exhausted && pageNum < maxPages; pageNum++ {
+		loaded, err := ss.scroll(ctx, page)
+		if err != nil {
+			return err
+		}
+		if !loaded {
+			ss.exhausted = true
+		}
+	}
+
+	return nil
+}
+
+// scroll 滚动到页面底部并等待新结果，没有新结果时重试，全部失败才视为没有更多结果
+func (ss *searchSession) scroll(ctx context.Context, page *rod.Page) (bool, error) {
+	loaded := len(ss.feeds)
+
+	for attempt := 1; attempt <= searchScrollAttempts; attempt++ {
+		if _, err := page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			return false, errors.Wrap(err, "滚动搜索结果失败")
+		}
+
+		err := waitUntil(ctx, searchScrollTimeout, "搜索结果加载", func() bool {
+			if more, err := readSearchFeeds(page); err == nil {
+				ss.feeds = mergeFeeds(ss.feeds, ss.seen, more)
+			}
+			ss.feeds = mergeFeeds(ss.feeds, ss.seen, ss.collector.Feeds(apiSearchNotes))
+			return len(ss.feeds) > loaded
+		})
+		if err == nil {
+			return true, nil
+		}
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		logrus.Debugf("%v（第%d次），共加载%d条结果", err, attempt, loaded)
+	}
+
+	return false, nil
+}
+
+func newSearchSessionID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "生成搜索会话ID失败")
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// readSearchFeeds 读取当前页面状态中的全部搜索结果
+func readSearchFeeds(page *rod.Page) ([]Feed, error) {
+	result, err := page.Eval(`() => {
+		const feeds = window.__INITIAL_STATE__?.search?.feeds;
+		return feeds ? JSON.stringify({search: {feeds}}) : "";
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSearchState([]byte(result.Value.String()))
+}
+
+// parseSearchState 从搜索页 __INITIAL_STATE__ 的 JSON 中解析搜索结果
+func parseSearchState(data []byte) ([]Feed, error) {
+	if len(data) == 0 {
 		return nil, fmt.Errorf("__INITIAL_STATE__ not found")
 	}
 
-	var searchResult SearchResult
-	if err := json.Unmarshal([]byte(result), &searchResult); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal __INITIAL_STATE__: %w", err)
+	var state SearchResult
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search feeds: %w", err)
+	}
+
+	return normalizeFeeds(state.Search.Feeds.Value), nil
+}
+
+// mergeFeeds 将新读取的结果追加到 feeds 中，跳过已存在和没有 ID 的项
+func mergeFeeds(feeds []Feed, seen map[string]bool, more []Feed) []Feed {
+	for _, feed := range more {
+		if feed.ID == "" || seen[feed.ID] {
+			continue
+		}
+		seen[feed.ID] = true
+		feeds = append(feeds, feed)
+	}
+	return feeds
+}
+
+// paginateFeeds 从全部结果中截取 [offset, offset+limit) 的一页。
+// exhausted 表示页面已没有更多结果可加载，sessionID 写入下一页游标。
+func paginateFeeds(feeds []Feed, offset, limit int, exhausted bool, sessionID string) *SearchPage {
+	result := &SearchPage{Feeds: []Feed{}}
+	if offset >= len(feeds) {
+		return result
+	}
+
+	end := offset + limit
+	if end > len(feeds) {
+		end = len(feeds)
+	}
+	result.Feeds = feeds[offset:end]
+
+	result.HasMore = end < len(feeds) || !exhausted
+	if result.HasMore {
+		result.NextCursor = formatSearchCursor(sessionID, end)
+	}
+
+	return result
+}
+
+// formatSearchCursor 生成 "会话ID:偏移量" 形式的游标
+func formatSearchCursor(sessionID string, offset int) string {
+	if sessionID == "" {
+		return strconv.Itoa(offset)
+	}
+	return sessionID + ":" + strconv.Itoa(offset)
+}
+
+// parseSearchCursor 解析游标，兼容只有偏移量的旧格式
+func parseSearchCursor(cursor string) (sessionID string, offset int, err error) {
+	if cursor == "" {
+		return "", 0, nil
+	}
+
+	raw := cursor
+	if i := strings.LastIndex(cursor, ":"); i >= 0 {
+		sessionID, raw = cursor[:i], cursor[i+1:]
+		if sessionID == "" {
+			return "", 0, ErrInvalidCursor
+		}
+	}
+
+	offset, err = strconv.Atoi(raw)
+	if err != nil || offset < 0 {
+		return "", 0, ErrInvalidCursor
 	}
 
-	return searchResult.Search.Feeds.Value, nil
+	return sessionID, offset, nil
 }
 
 func makeSearchURL(keyword string) string {
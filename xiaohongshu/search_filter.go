@@ -0,0 +1,199 @@
+package xiaohongshu
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// SearchSort 搜索结果排序方式
+type SearchSort string
+
+const (
+	SearchSortGeneral       SearchSort = "general"        // 综合
+	SearchSortLatest        SearchSort = "latest"         // 最新
+	SearchSortMostLiked     SearchSort = "most_liked"     // 最多点赞
+	SearchSortMostCommented SearchSort = "most_commented" // 最多评论
+	SearchSortMostCollected SearchSort = "most_collected" // 最多收藏
+)
+
+// SearchNoteType 搜索结果笔记类型
+type SearchNoteType string
+
+const (
+	SearchNoteTypeAll   SearchNoteType = "all"   // 不限
+	SearchNoteTypeVideo SearchNoteType = "video" // 视频
+	SearchNoteTypeImage SearchNoteType = "image" // 图文
+)
+
+// SearchPublishTime 搜索结果发布时间范围
+type SearchPublishTime string
+
+const (
+	SearchPublishTimeAll      SearchPublishTime = "all"       // 不限
+	SearchPublishTimeDay      SearchPublishTime = "day"       // 一天内
+	SearchPublishTimeWeek     SearchPublishTime = "week"      // 一周内
+	SearchPublishTimeHalfYear SearchPublishTime = "half_year" // 半年内
+)
+
+// 筛选项在搜索页筛选面板中对应的文案
+var (
+	searchSortLabels = map[SearchSort]string{
+		SearchSortGeneral:       "综合",
+		SearchSortLatest:        "最新",
+		SearchSortMostLiked:     "最多点赞",
+		SearchSortMostCommented: "最多评论",
+		SearchSortMostCollected: "最多收藏",
+	}
+	searchNoteTypeLabels = map[SearchNoteType]string{
+		SearchNoteTypeAll:   "不限",
+		SearchNoteTypeVideo: "视频",
+		SearchNoteTypeImage: "图文",
+	}
+	searchPublishTimeLabels = map[SearchPublishTime]string{
+		SearchPublishTimeAll:      "不限",
+		SearchPublishTimeDay:      "一天内",
+		SearchPublishTimeWeek:     "一周内",
+		SearchPublishTimeHalfYear: "半年内",
+	}
+)
+
+// SearchFilters 搜索筛选条件，零值表示使用页面默认值
+type SearchFilters struct {
+	Sort        SearchSort
+	NoteType    SearchNoteType
+	PublishTime SearchPublishTime
+}
+
+// ParseSearchFilters 解析并校验筛选参数，空字符串表示默认值
+func ParseSearchFilters(sortBy, noteType, publishTime string) (SearchFilters, error) {
+	var filters SearchFilters
+
+	s, err := parseSearchEnum(sortBy, searchSortLabels, "排序方式")
+	if err != nil {
+		return filters, err
+	}
+	n, err := parseSearchEnum(noteType, searchNoteTypeLabels, "笔记类型")
+	if err != nil {
+		return filters, err
+	}
+	p, err := parseSearchEnum(publishTime, searchPublishTimeLabels, "发布时间")
+	if err != nil {
+		return filters, err
+	}
+
+	filters.Sort, filters.NoteType, filters.PublishTime = s, n, p
+	return filters, nil
+}
+
+func parseSearchEnum[T ~string](s string, labels map[T]string, name string) (T, error) {
+	v := T(strings.ToLower(strings.TrimSpace(s)))
+	if v == "" {
+		return v, nil
+	}
+
+	if _, ok := labels[v]; !ok {
+		options := make([]string, 0, len(labels))
+		for k := range labels {
+			options = append(options, string(k))
+		}
+		sort.Strings(options)
+		return "", errors.Errorf("不支持的%s: %s（可选 %s）", name, s, strings.Join(options, "、"))
+	}
+
+	return v, nil
+}
+
+// searchFilterClick 需要在筛选面板中点击的分组与选项文案
+type searchFilterClick struct {
+	Group string
+	Label string
+}
+
+// clicks 返回需要点击的筛选项，默认值不点击
+func (f SearchFilters) clicks() []searchFilterClick {
+	var clicks []searchFilterClick
+
+	if f.Sort != "" && f.Sort != SearchSortGeneral {
+		clicks = append(clicks, searchFilterClick{Group: "排序依据", Label: searchSortLabels[f.Sort]})
+	}
+	if f.NoteType != "" && f.NoteType != SearchNoteTypeAll {
+		clicks = append(clicks, searchFilterClick{Group: "笔记类型", Label: searchNoteTypeLabels[f.NoteType]})
+	}
+	if f.PublishTime != "" && f.PublishTime != SearchPublishTimeAll {
+		clicks = append(clicks, searchFilterClick{Group: "发布时间", Label: searchPublishTimeLabels[f.PublishTime]})
+	}
+
+	return clicks
+}
+
+// applySearchFilters 打开搜索页筛选面板并依次点击筛选项，等待搜索结果刷新
+func applySearchFilters(page *rod.Page, filters SearchFilters) error {
+	clicks := filters.clicks()
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	trigger, err := page.Timeout(10*time.Second).ElementR("div.filter", "筛选")
+	if err != nil {
+		return errors.Wrap(err, "未找到筛选按钮")
+	}
+
+	// 筛选面板在鼠标悬停时展开
+	if err := trigger.Hover(); err != nil {
+		return errors.Wrap(err, "打开筛选面板失败")
+	}
+
+	panel, err := page.Timeout(5 * time.Second).Element("div.filter-panel")
+	if err != nil {
+		return errors.Wrap(err, "筛选面板未展开")
+	}
+
+	for _, click := range clicks {
+		before, _ := readSearchFeeds(page)
+
+		group, err := panel.ElementR("div.filters", click.Group)
+		if err != nil {
+			return errors.Wrapf(err, "未找到筛选分组: %s", click.Group)
+		}
+
+		tag, err := group.ElementR("div.tags, span", "^"+click.Label+"$")
+		if err != nil {
+			return errors.Wrapf(err, "未找到筛选项: %s", click.Label)
+		}
+
+		if err := tag.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return errors.Wrapf(err, "点击筛选项失败: %s", click.Label)
+		}
+
+		// 结果未刷新时读到的是筛选前的笔记，不能当作筛选结果返回
+		if err := waitForSearchRefresh(page, before); err != nil {
+			return errors.Wrapf(err, "应用筛选项后搜索结果未刷新: %s", click.Label)
+		}
+
+		logrus.Infof("已应用搜索筛选: %s=%s", click.Group, click.Label)
+	}
+
+	// 移开鼠标收起筛选面板
+	if err := page.Mouse.MoveTo(proto.Point{X: 0, Y: 0}); err != nil {
+		logrus.Debugf("收起筛选面板失败: %v", err)
+	}
+
+	return nil
+}
+
+// waitForSearchRefresh 等待搜索结果与点击前不同
+func waitForSearchRefresh(page *rod.Page, before []Feed) error {
+	return waitUntil(page.GetContext(), 10*time.Second, "搜索结果刷新", func() bool {
+		after, err := readSearchFeeds(page)
+		if err != nil || len(after) == 0 {
+			return false
+		}
+		return len(before) == 0 || after[0].ID != before[0].ID || len(after) != len(before)
+	})
+}
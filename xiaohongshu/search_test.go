@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xpzouying/xiaohongshu-mcp/browser"
 )
@@ -16,19 +17,95 @@ func TestSearch(t *testing.T) {
 	b := browser.NewBrowser(false)
 	defer b.Close()
 
-	page := b.NewPage()
-	defer page.Close()
+	sessions := NewSearchSessions(b.NewPage)
 
-	action := NewSearchAction(page)
-
-	feeds, err := action.Search(context.Background(), "Kimi")
+	result, err := sessions.Search(context.Background(), "Kimi", SearchOptions{Limit: 40})
 	require.NoError(t, err)
-	require.NotEmpty(t, feeds, "feeds should not be empty")
+	require.NotEmpty(t, result.Feeds, "feeds should not be empty")
 
-	fmt.Printf("成功获取到 %d 个 Feed\n", len(feeds))
+	fmt.Printf("成功获取到 %d 个 Feed，下一页游标: %s\n", len(result.Feeds), result.NextCursor)
 
-	for _, feed := range feeds {
+	for _, feed := range result.Feeds {
 		fmt.Printf("Feed ID: %s\n", feed.ID)
 		fmt.Printf("Feed Title: %s\n", feed.NoteCard.DisplayTitle)
 	}
 }
+
+func TestMergeFeeds(t *testing.T) {
+	seen := make(map[string]bool)
+
+	feeds := mergeFeeds(nil, seen, []Feed{{ID: "a"}, {ID: "b"}, {ID: ""}})
+	feeds = mergeFeeds(feeds, seen, []Feed{{ID: "b"}, {ID: "c"}})
+
+	require.Len(t, feeds, 3)
+	assert.Equal(t, "c", feeds[2].ID)
+}
+
+func TestPaginateFeeds(t *testing.T) {
+	feeds := []Feed{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}
+
+	page := paginateFeeds(feeds, 0, 2, false, "s1")
+	assert.Len(t, page.Feeds, 2)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "s1:2", page.NextCursor)
+
+	// 已加载的结果多于本页时仍有下一页
+	page = paginateFeeds(feeds, 2, 2, true, "s1")
+	assert.Equal(t, "c", page.Feeds[0].ID)
+	assert.Equal(t, "s1:4", page.NextCursor)
+
+	page = paginateFeeds(feeds, 4, 2, true, "s1")
+	assert.Len(t, page.Feeds, 1)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+
+	page = paginateFeeds(feeds, 10, 2, true, "s1")
+	assert.Empty(t, page.Feeds)
+	assert.False(t, page.HasMore)
+}
+
+func TestParseSearchCursor(t *testing.T) {
+	id, offset, err := parseSearchCursor("")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	assert.Equal(t, 0, offset)
+
+	// 只有偏移量的旧格式
+	id, offset, err = parseSearchCursor("40")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	assert.Equal(t, 40, offset)
+
+	id, offset, err = parseSearchCursor(formatSearchCursor("a1b2", 20))
+	require.NoError(t, err)
+	assert.Equal(t, "a1b2", id)
+	assert.Equal(t, 20, offset)
+
+	for _, cursor := range []string{"abc", "-1", ":20", "a1b2:x"} {
+		_, _, err = parseSearchCursor(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
+
+func TestParseSearchFilters(t *testing.T) {
+	filters, err := ParseSearchFilters("LATEST", "video", "")
+	require.NoError(t, err)
+	assert.Equal(t, SearchSortLatest, filters.Sort)
+	assert.Equal(t, SearchNoteTypeVideo, filters.NoteType)
+	assert.Equal(t, SearchPublishTime(""), filters.PublishTime)
+
+	assert.Equal(t, []searchFilterClick{
+		{Group: "排序依据", Label: "最新"},
+		{Group: "笔记类型", Label: "视频"},
+	}, filters.clicks())
+
+	// 默认值不需要点击
+	filters, err = ParseSearchFilters("general", "all", "all")
+	require.NoError(t, err)
+	assert.Empty(t, filters.clicks())
+
+	_, err = ParseSearchFilters("hot", "", "")
+	assert.Error(t, err)
+	_, err = ParseSearchFilters("", "", "month")
+	assert.Error(t, err)
+}
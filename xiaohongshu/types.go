@@ -24,6 +24,7 @@ type Feed struct {
 	ModelType string   `json:"modelType"`
 	NoteCard  NoteCard `json:"noteCard"`
 	Index     int      `json:"index"`
+	URL       string   `json:"url,omitempty"` // 笔记详情页地址，由 ID 和 xsecToken 拼接
 }
 
 // NoteCard 表示笔记卡片信息
@@ -33,14 +34,16 @@ type NoteCard struct {
 	User         User         `json:"user"`
 	InteractInfo InteractInfo `json:"interactInfo"`
 	Cover        Cover        `json:"cover"`
-	Video        *Video       `json:"video,omitempty"` // 视频内容，可能为空
+	ImageList    []Cover      `json:"imageList,omitempty"` // 搜索结果中的完整图片列表，首页卡片没有
+	Images       []string     `json:"images"`              // 全部图片的最佳地址，没有图片列表时为封面
+	Video        *Video       `json:"video,omitempty"`     // 视频内容，可能为空
 }
 
 // User 表示用户信息
 type User struct {
 	UserID    string `json:"userId"`
 	Nickname  string `json:"nickname"`
-	NickName  string `json:"nickName"`
+	NickName  string `json:"nickName,omitempty"` // 页面部分位置使用的昵称字段，解析后合并到 Nickname
 	Avatar    string `json:"avatar"`
 	XsecToken string `json:"xsecToken"`
 }
@@ -55,6 +58,12 @@ type InteractInfo struct {
 
 	CollectedCount string `json:"collectedCount"`
 	Collected      bool   `json:"collected"`
+
+	// 由上面的计数字符串（如 "1.2万"）解析出的数值
+	LikedNum     int64 `json:"likedNum"`
+	SharedNum    int64 `json:"sharedNum"`
+	CommentNum   int64 `json:"commentNum"`
+	CollectedNum int64 `json:"collectedNum"`
 }
 
 // Cover 表示封面信息
@@ -76,10 +85,128 @@ type ImageInfo struct {
 
 // Video 表示视频信息
 type Video struct {
-	Capa VideoCapability `json:"capa"`
+	Capa  VideoCapability `json:"capa"`
+	Media *VideoMedia     `json:"media,omitempty"` // 视频流地址，仅笔记详情中有
+}
+
+// VideoMedia 视频媒体信息
+type VideoMedia struct {
+	Stream VideoStreams `json:"stream"`
+}
+
+// VideoStreams 按编码分组的视频流
+type VideoStreams struct {
+	H264 []VideoStream `json:"h264"`
+	H265 []VideoStream `json:"h265"`
+}
+
+// VideoStream 单个清晰度的视频流
+type VideoStream struct {
+	MasterURL  string   `json:"masterUrl"`
+	BackupURLs []string `json:"backupUrls"`
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	Size       int64    `json:"size"` // 字节数
+	Format     string   `json:"format"`
 }
 
 // VideoCapability 表示视频能力信息
 type VideoCapability struct {
 	Duration int `json:"duration"` // 视频时长，单位秒
 }
+
+// FeedDetailResponse 笔记详情及第一页评论
+type FeedDetailResponse struct {
+	Note     FeedDetail  `json:"note"`
+	Comments CommentList `json:"comments"`
+}
+
+// FeedDetail 笔记详情，对应 __INITIAL_STATE__.note.noteDetailMap[id].note
+type FeedDetail struct {
+	NoteID       string            `json:"noteId"`
+	XsecToken    string            `json:"xsecToken"`
+	Title        string            `json:"title"`
+	Desc         string            `json:"desc"`
+	Type         string            `json:"type"`
+	Time         int64             `json:"time"`           // 发布时间，毫秒时间戳
+	LastUpdate   int64             `json:"lastUpdateTime"` // 最后修改时间，毫秒时间戳
+	IPLocation   string            `json:"ipLocation"`
+	User         User              `json:"user"`
+	InteractInfo InteractInfo      `json:"interactInfo"`
+	ImageList    []DetailImageInfo `json:"imageList"`
+	TagList      []Tag             `json:"tagList"`
+	Video        *Video            `json:"video,omitempty"`
+	URL          string            `json:"url,omitempty"` // 笔记详情页地址
+}
+
+// DetailImageInfo 笔记详情中的图片
+type DetailImageInfo struct {
+	Width      int         `json:"width"`
+	Height     int         `json:"height"`
+	URLDefault string      `json:"urlDefault"`
+	URLPre     string      `json:"urlPre"`
+	InfoList   []ImageInfo `json:"infoList,omitempty"`
+	LivePhoto  bool        `json:"livePhoto,omitempty"`
+}
+
+// Tag 笔记话题标签
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// CommentList 评论列表
+type CommentList struct {
+	List    []Comment `json:"list"`
+	Cursor  string    `json:"cursor"`
+	HasMore bool      `json:"hasMore"`
+}
+
+// Comment 评论
+type Comment struct {
+	ID              string    `json:"id"`
+	NoteID          string    `json:"noteId"`
+	Content         string    `json:"content"`
+	LikeCount       string    `json:"likeCount"`
+	LikeNum         int64     `json:"likeNum"`
+	Liked           bool      `json:"liked"`
+	CreateTime      int64     `json:"createTime"` // 毫秒时间戳
+	IPLocation      string    `json:"ipLocation"`
+	UserInfo        User      `json:"userInfo"`
+	SubCommentCount string    `json:"subCommentCount"`
+	SubComments     []Comment `json:"subComments"`
+}
+
+// UserProfileResponse 用户主页信息及笔记列表
+type UserProfileResponse struct {
+	BasicInfo    UserBasicInfo     `json:"basicInfo"`
+	Interactions []UserInteraction `json:"interactions"`
+	Tags         []UserTag         `json:"tags"`
+	Feeds        []Feed            `json:"feeds"`
+	HasMore      bool              `json:"hasMore"` // 是否还有未加载的笔记
+}
+
+// UserBasicInfo 用户基本信息
+type UserBasicInfo struct {
+	Nickname   string `json:"nickname"`
+	RedID      string `json:"redId"`
+	Desc       string `json:"desc"`
+	Gender     int    `json:"gender"`
+	IPLocation string `json:"ipLocation"`
+	Images     string `json:"images"` // 头像地址
+}
+
+// UserInteraction 用户关注数、粉丝数、获赞与收藏数
+type UserInteraction struct {
+	Type  string `json:"type"` // follows、fans、interaction
+	Name  string `json:"name"`
+	Count string `json:"count"`
+	Num   int64  `json:"num"` // 由 Count 解析出的数值
+}
+
+// UserTag 用户主页展示的标签（地区、职业等）
+type UserTag struct {
+	TagType string `json:"tagType"`
+	Name    string `json:"name"`
+}
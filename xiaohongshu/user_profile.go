@@ -0,0 +1,157 @@
+package xiaohongshu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUserNotFound 用户不存在或主页无法访问
+var ErrUserNotFound = errors.New("用户不存在或主页无法访问")
+
+const (
+	// defaultUserNoteLimit 未指定数量时返回的笔记数，即主页首屏数量
+	defaultUserNoteLimit = 30
+	// userNotesScrollTimeout 每次滚动后等待新笔记加载的最长时间
+	userNotesScrollTimeout = 5 * time.Second
+)
+
+// userPageState 对应 __INITIAL_STATE__.user 中与主页相关的字段
+type userPageState struct {
+	UserPageData struct {
+		BasicInfo    UserBasicInfo     `json:"basicInfo"`
+		Interactions []UserInteraction `json:"interactions"`
+		Tags         []UserTag         `json:"tags"`
+	} `json:"userPageData"`
+	// Notes 按主页标签页分组，第一组为用户发布的笔记
+	Notes       [][]Feed `json:"notes"`
+	NoteQueries []struct {
+		HasMore bool `json:"hasMore"`
+	} `json:"noteQueries"`
+}
+
+type UserProfileAction struct {
+	page *rod.Page
+}
+
+func NewUserProfileAction(page *rod.Page) *UserProfileAction {
+	pp := page.Timeout(60 * time.Second)
+
+	return &UserProfileAction{page: pp}
+}
+
+// GetUserProfile 打开用户主页，读取用户信息和笔记列表。
+// limit 为返回笔记数量上限，超过首屏数量时滚动加载，<=0 时使用默认值。
+func (u *UserProfileAction) GetUserProfile(ctx context.Context, userID, xsecToken string, limit int) (*UserProfileResponse, error) {
+	page := u.page.Context(ctx)
+
+	if limit <= 0 {
+		limit = defaultUserNoteLimit
+	}
+
+	if err := page.Navigate(makeUserProfileURL(userID, xsecToken)); err != nil {
+		return nil, errors.Wrap(err, "打开用户主页失败")
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return nil, errors.Wrap(err, "等待用户主页加载失败")
+	}
+
+	var profile *UserProfileResponse
+	if err := waitUntil(ctx, 15*time.Second, "用户主页数据加载", func() bool {
+		p, err := readUserProfile(page)
+		if err != nil {
+			return false
+		}
+		profile = p
+		return true
+	}); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	for len(profile.Feeds) < limit && profile.HasMore {
+		loaded := len(profile.Feeds)
+
+		if _, err := page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			logrus.Debugf("滚动用户主页失败: %v", err)
+			break
+		}
+
+		if err := waitUntil(ctx, userNotesScrollTimeout, "用户笔记加载", func() bool {
+			p, err := readUserProfile(page)
+			if err != nil || len(p.Feeds) <= loaded {
+				return false
+			}
+			profile = p
+			return true
+		}); err != nil {
+			logrus.Debugf("%v，返回已加载的%d篇笔记", err, loaded)
+			break
+		}
+	}
+
+	if len(profile.Feeds) > limit {
+		profile.Feeds = profile.Feeds[:limit]
+		profile.HasMore = true
+	}
+
+	return profile, nil
+}
+
+func readUserProfile(page *rod.Page) (*UserProfileResponse, error) {
+	res, err := page.Eval(`() => {
+		const user = window.__INITIAL_STATE__ && window.__INITIAL_STATE__.user;
+		if (!user) return "";
+		const unwrap = v => v && (v._rawValue !== undefined ? v._rawValue : (v._value !== undefined ? v._value : v));
+		return JSON.stringify({
+			userPageData: unwrap(user.userPageData),
+			notes: unwrap(user.notes),
+			noteQueries: unwrap(user.noteQueries),
+		});
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseUserProfile([]byte(res.Value.String()))
+}
+
+// parseUserProfile 解析用户主页状态 JSON，用户信息为空时返回 ErrUserNotFound
+func parseUserProfile(data []byte) (*UserProfileResponse, error) {
+	if len(data) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	var state userPageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user state: %w", err)
+	}
+
+	if state.UserPageData.BasicInfo.Nickname == "" {
+		return nil, ErrUserNotFound
+	}
+
+	profile := &UserProfileResponse{
+		BasicInfo:    state.UserPageData.BasicInfo,
+		Interactions: state.UserPageData.Interactions,
+		Tags:         state.UserPageData.Tags,
+	}
+
+	for i := range profile.Interactions {
+		profile.Interactions[i].Num = parseCount(profile.Interactions[i].Count)
+	}
+
+	if len(state.Notes) > 0 {
+		profile.Feeds = normalizeFeeds(state.Notes[0])
+	}
+	if len(state.NoteQueries) > 0 {
+		profile.HasMore = state.NoteQueries[0].HasMore
+	}
+
+	return profile, nil
+}
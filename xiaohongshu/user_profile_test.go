@@ -0,0 +1,49 @@
+package xiaohongshu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userStateJSON = `{
+	"userPageData": {
+		"basicInfo": {"nickname": "茶小白", "redId": "123456", "desc": "每天一杯养生茶", "gender": 1, "ipLocation": "湖南"},
+		"interactions": [
+			{"type": "follows", "name": "关注", "count": "12"},
+			{"type": "fans", "name": "粉丝", "count": "3.4万"},
+			{"type": "interaction", "name": "获赞与收藏", "count": "10万+"}
+		],
+		"tags": [{"tagType": "location", "name": "湖南长沙"}]
+	},
+	"notes": [
+		[
+			{"id": "n1", "xsecToken": "t1", "noteCard": {"type": "normal", "displayTitle": "春季养生茶"}},
+			{"id": "n2", "xsecToken": "t2", "noteCard": {"type": "video", "displayTitle": "泡茶教程"}}
+		],
+		[],
+		[]
+	],
+	"noteQueries": [{"hasMore": true}, {"hasMore": false}]
+}`
+
+func TestParseUserProfile(t *testing.T) {
+	profile, err := parseUserProfile([]byte(userStateJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, "茶小白", profile.BasicInfo.Nickname)
+	assert.Equal(t, "每天一杯养生茶", profile.BasicInfo.Desc)
+	require.Len(t, profile.Interactions, 3)
+	assert.Equal(t, "fans", profile.Interactions[1].Type)
+	assert.Equal(t, "3.4万", profile.Interactions[1].Count)
+	require.Len(t, profile.Tags, 1)
+
+	require.Len(t, profile.Feeds, 2)
+	assert.Equal(t, "n2", profile.Feeds[1].ID)
+	assert.Equal(t, "泡茶教程", profile.Feeds[1].NoteCard.DisplayTitle)
+	assert.True(t, profile.HasMore)
+
+	_, err = parseUserProfile([]byte(`{"userPageData": {"basicInfo": {}}}`))
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,32 @@
+package xiaohongshu
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// waitPollInterval 条件等待的轮询间隔
+const waitPollInterval = 200 * time.Millisecond
+
+// waitUntil 轮询直到 cond 返回 true，超时返回错误，ctx 结束时返回 ctx.Err()，desc 用于描述等待的条件
+func waitUntil(ctx context.Context, timeout time.Duration, desc string, cond func() bool) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if cond() {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return errors.Errorf("等待%s超时（%s）", desc, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package xiaohongshu
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitUntil(t *testing.T) {
+	calls := 0
+	err := waitUntil(context.Background(), time.Second, "条件", func() bool {
+		calls++
+		return calls == 2
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	err = waitUntil(context.Background(), 0, "条件", func() bool { return false })
+	assert.ErrorContains(t, err, "等待条件超时")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = waitUntil(ctx, time.Minute, "条件", func() bool { return false })
+	assert.ErrorIs(t, err, context.Canceled)
+}